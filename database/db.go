@@ -5,18 +5,73 @@ import (
     "os"
     "github.com/sirupsen/logrus"
     "gorm.io/driver/postgres"
+    "gorm.io/driver/sqlite"
     "gorm.io/gorm"
 )
 
-// InitDB инициализирует подключение к базе данных
-func InitDB() *gorm.DB {
+// Поддерживаемые драйверы базы данных (DB_DRIVER)
+const (
+    DriverPostgres = "postgres"
+    DriverSQLite   = "sqlite"
+)
+
+// Config параметры подключения к базе данных
+type Config struct {
+    Driver string // postgres или sqlite
+    Path   string // Файл базы SQLite или :memory:
+}
+
+// LoadConfig читает DB_DRIVER (по умолчанию postgres) и DB_PATH для SQLite
+func LoadConfig() (Config, error) {
+    cfg := Config{Driver: os.Getenv("DB_DRIVER"), Path: os.Getenv("DB_PATH")}
+    if cfg.Driver == "" {
+        cfg.Driver = DriverPostgres
+    }
+    switch cfg.Driver {
+    case DriverPostgres:
+    case DriverSQLite:
+        if cfg.Path == "" {
+            cfg.Path = "person-api.db"
+        }
+    default:
+        return cfg, fmt.Errorf("неизвестный DB_DRIVER %q: ожидается %s или %s", cfg.Driver, DriverPostgres, DriverSQLite)
+    }
+    return cfg, nil
+}
+
+// Open открывает соединение с базой по конфигурации
+func Open(cfg Config) (*gorm.DB, error) {
+    if cfg.Driver == DriverSQLite {
+        return openSQLite(cfg.Path)
+    }
     // Формируем DSN для подключения
     dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
         os.Getenv("DB_HOST"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"),
         os.Getenv("DB_NAME"), os.Getenv("DB_PORT"))
-    logrus.Info("Подключение к базе данных...")
+    return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
+
+// openSQLite открывает файл SQLite (или базу в памяти) через драйвер с Unicode-функцией lower
+func openSQLite(path string) (*gorm.DB, error) {
+    dsn := path + "?_foreign_keys=on&_busy_timeout=5000"
+    db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: dsn}), &gorm.Config{})
+    if err != nil {
+        return nil, err
+    }
+    // SQLite допускает одного писателя, а каждое соединение к :memory: — отдельная база
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    sqlDB.SetMaxOpenConns(1)
+    return db, nil
+}
+
+// InitDB инициализирует подключение к базе данных
+func InitDB(cfg Config) *gorm.DB {
+    logrus.Infof("Подключение к базе данных (%s)...", cfg.Driver)
     // Открываем соединение с базой
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+    db, err := Open(cfg)
     if err != nil {
         logrus.Fatal("Ошибка подключения к базе: ", err)
     }
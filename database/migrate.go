@@ -1,28 +1,217 @@
 package database
 
 import (
+    "context"
+    "database/sql"
+    "errors"
     "fmt"
+    "io/fs"
     "os"
+    "path/filepath"
+    "person-api/migrations"
+    "regexp"
+    "strings"
     "github.com/golang-migrate/migrate/v4"
-    _ "github.com/golang-migrate/migrate/v4/database/postgres"
-    _ "github.com/golang-migrate/migrate/v4/source/file"
+    "github.com/golang-migrate/migrate/v4/database"
+    "github.com/golang-migrate/migrate/v4/database/postgres"
+    "github.com/golang-migrate/migrate/v4/database/sqlite3"
+    "github.com/golang-migrate/migrate/v4/source/iofs"
+    "github.com/sirupsen/logrus"
+    "gorm.io/gorm"
 )
 
-// RunMigrations выполняет миграции базы данных
-func RunMigrations() error {
-    // Формируем DSN для подключения к PostgreSQL
-    dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-        os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_HOST"),
-        os.Getenv("DB_PORT"), os.Getenv("DB_NAME"))
-    // Инициализируем миграции из папки migrations
-    m, err := migrate.New("file://migrations", dsn)
+// migrationsFS возвращает встроенные в бинарный файл миграции или, если задан
+// MIGRATIONS_DIR, каталог на диске (удобно при разработке новых миграций)
+func migrationsFS() fs.FS {
+    if dir := os.Getenv("MIGRATIONS_DIR"); dir != "" {
+        logrus.Infof("Миграции загружаются с диска: %s", dir)
+        return os.DirFS(dir)
+    }
+    return migrations.FS
+}
+
+// RunMigrations выполняет миграции для драйвера базы db
+// (postgres/ или sqlite/ во встроенных миграциях)
+func RunMigrations(db *gorm.DB) error {
+    m, err := NewMigrator(db)
     if err != nil {
         return err
     }
     defer m.Close()
     // Применяем миграции
-    if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-        return err
+    return m.Up(0)
+}
+
+// Migrator управляет версией схемы базы: применяет и откатывает миграции
+type Migrator struct {
+    m     *migrate.Migrate
+    close func()
+}
+
+// NewMigrator создаёт Migrator поверх пула соединений db. Close освобождает
+// источник миграций и выделенное соединение, не закрывая сам пул.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    driver := Driver(db)
+    target, closeTarget, err := migrateTarget(sqlDB, driver)
+    if err != nil {
+        return nil, err
+    }
+    // Инициализируем миграции из папки драйвера
+    src, err := iofs.New(migrationsFS(), driver)
+    if err != nil {
+        closeTarget()
+        return nil, err
+    }
+    m, err := migrate.NewWithInstance("iofs", src, driver, target)
+    if err != nil {
+        src.Close()
+        closeTarget()
+        return nil, err
+    }
+    m.Log = migrateLogger{}
+    return &Migrator{m: m, close: func() {
+        src.Close()
+        closeTarget()
+    }}, nil
+}
+
+// Up применяет n следующих миграций или все, если n <= 0
+func (m *Migrator) Up(n int) error {
+    var err error
+    if n > 0 {
+        err = m.m.Steps(n)
+    } else {
+        err = m.m.Up()
+    }
+    return ignoreNoChange(err)
+}
+
+// Down откатывает n последних миграций или все, если n <= 0
+func (m *Migrator) Down(n int) error {
+    var err error
+    if n > 0 {
+        err = m.m.Steps(-n)
+    } else {
+        err = m.m.Down()
+    }
+    return ignoreNoChange(err)
+}
+
+// Version возвращает текущую версию схемы (0 — миграции не применялись)
+// и признак dirty после прерванной миграции
+func (m *Migrator) Version() (uint, bool, error) {
+    version, dirty, err := m.m.Version()
+    if errors.Is(err, migrate.ErrNilVersion) {
+        return 0, false, nil
+    }
+    return version, dirty, err
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак dirty.
+// Версия -1 означает «миграции не применялись».
+func (m *Migrator) Force(version int) error {
+    return m.m.Force(version)
+}
+
+// Close освобождает ресурсы Migrator
+func (m *Migrator) Close() {
+    m.close()
+}
+
+// ignoreNoChange считает успехом отсутствие миграций для применения
+// и выполнение всех оставшихся, если их меньше запрошенного количества
+func ignoreNoChange(err error) error {
+    var short migrate.ErrShortLimit
+    if errors.As(err, &short) {
+        logrus.Warnf("Выполнены все доступные миграции, на %d меньше запрошенного", short.Short)
+        return nil
+    }
+    if errors.Is(err, migrate.ErrNoChange) {
+        return nil
+    }
+    return err
+}
+
+// migrateLogger выводит ход миграций через logrus
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+    logrus.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+    return false
+}
+
+// migrateTarget возвращает драйвер migrate для базы и функцию его закрытия
+func migrateTarget(sqlDB *sql.DB, driver string) (database.Driver, func() error, error) {
+    if driver == DriverSQLite {
+        // База в памяти существует только внутри пула, поэтому мигрируем через него же.
+        // Close драйвера sqlite3 закрыл бы весь пул, поэтому не вызываем его.
+        target, err := sqlite3.WithInstance(sqlDB, &sqlite3.Config{})
+        return target, func() error { return nil }, err
+    }
+    // Для PostgreSQL берём из пула отдельное соединение: на нём держится advisory lock
+    ctx := context.Background()
+    conn, err := sqlDB.Conn(ctx)
+    if err != nil {
+        return nil, nil, err
+    }
+    target, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+    if err != nil {
+        conn.Close()
+        return nil, nil, err
+    }
+    return target, target.Close, nil
+}
+
+// Driver возвращает драйвер (postgres или sqlite), через который открыта db
+func Driver(db *gorm.DB) string {
+    if db.Dialector.Name() == DriverSQLite {
+        return DriverSQLite
+    }
+    return DriverPostgres
+}
+
+// migrationName допустимое имя новой миграции
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// CreateMigration создаёт пустые up/down-файлы миграции name со следующим номером
+// в каталогах всех драйверов внутри dir и возвращает их пути
+func CreateMigration(dir, name string) ([]string, error) {
+    if !migrationName.MatchString(name) {
+        return nil, fmt.Errorf("имя миграции %q: допустимы строчные латинские буквы, цифры и _", name)
+    }
+    drivers := []string{DriverPostgres, DriverSQLite}
+    // Номер общий для всех драйверов, чтобы версии схемы совпадали
+    var last uint
+    for _, driver := range drivers {
+        entries, err := os.ReadDir(filepath.Join(dir, driver))
+        if err != nil {
+            return nil, err
+        }
+        for _, entry := range entries {
+            var version uint
+            if _, err := fmt.Sscanf(entry.Name(), "%d_", &version); err == nil && version > last {
+                last = version
+            }
+        }
+    }
+    var created []string
+    for _, driver := range drivers {
+        for _, direction := range []string{"up", "down"} {
+            path := filepath.Join(dir, driver, fmt.Sprintf("%03d_%s.%s.sql", last+1, name, direction))
+            f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+            if err != nil {
+                return created, err
+            }
+            f.Close()
+            created = append(created, path)
+        }
     }
-    return nil
+    return created, nil
 }
@@ -0,0 +1,174 @@
+package database
+
+import (
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "testing"
+    "person-api/migrations"
+    "person-api/models"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// memoryConfig база SQLite в памяти
+var memoryConfig = Config{Driver: DriverSQLite, Path: ":memory:"}
+
+// latestVersion возвращает номер последней встроенной миграции драйвера
+func latestVersion(t *testing.T, driver string) uint {
+    entries, err := fs.ReadDir(migrations.FS, driver)
+    require.NoError(t, err)
+    var latest uint
+    for _, entry := range entries {
+        var version uint
+        if _, err := fmt.Sscanf(entry.Name(), "%d_", &version); err == nil && version > latest {
+            latest = version
+        }
+    }
+    return latest
+}
+
+// TestMigrationsMatch проверяет, что у каждой миграции есть версии для обоих драйверов
+func TestMigrationsMatch(t *testing.T) {
+    postgres, err := fs.Glob(migrations.FS, DriverPostgres+"/*.sql")
+    require.NoError(t, err)
+    sqlite, err := fs.Glob(migrations.FS, DriverSQLite+"/*.sql")
+    require.NoError(t, err)
+    for i := range postgres {
+        postgres[i] = filepath.Base(postgres[i])
+    }
+    for i := range sqlite {
+        sqlite[i] = filepath.Base(sqlite[i])
+    }
+    assert.Equal(t, postgres, sqlite)
+}
+
+// TestRunMigrationsEmbedded применяет встроенные миграции к SQLite в памяти
+func TestRunMigrationsEmbedded(t *testing.T) {
+    db, err := Open(memoryConfig)
+    require.NoError(t, err)
+    require.NoError(t, RunMigrations(db))
+    // Повторный запуск ничего не меняет
+    require.NoError(t, RunMigrations(db))
+    var version int
+    require.NoError(t, db.Raw("SELECT version FROM schema_migrations").Scan(&version).Error)
+    assert.Equal(t, int(latestVersion(t, DriverSQLite)), version)
+    // Схема совпадает с моделями, поиск без учёта регистра понимает кириллицу
+    person := models.Person{Name: "Дмитрий", Surname: "Ушаков", Enrichments: []models.PersonEnrichment{
+        {Provider: "rules", Field: "gender", Value: "male", Accepted: true},
+    }}
+    require.NoError(t, db.Create(&person).Error)
+    var found []models.Person
+    require.NoError(t, db.Where("lower(surname) LIKE ?", "%ушак%").Find(&found).Error)
+    assert.Len(t, found, 1)
+    // Окончательное удаление человека удаляет записи о происхождении
+    require.NoError(t, db.Unscoped().Delete(&models.Person{}, person.ID).Error)
+    var records int64
+    require.NoError(t, db.Model(&models.PersonEnrichment{}).Count(&records).Error)
+    assert.Zero(t, records)
+}
+
+// TestRunMigrationsFromDisk проверяет загрузку миграций из MIGRATIONS_DIR
+func TestRunMigrationsFromDisk(t *testing.T) {
+    dir := t.TempDir()
+    require.NoError(t, os.Mkdir(filepath.Join(dir, DriverSQLite), 0o755))
+    for _, name := range []string{"001_init.up.sql", "001_init.down.sql"} {
+        data, err := os.ReadFile(filepath.Join("..", "migrations", DriverSQLite, name))
+        require.NoError(t, err)
+        require.NoError(t, os.WriteFile(filepath.Join(dir, DriverSQLite, name), data, 0o644))
+    }
+    t.Setenv("MIGRATIONS_DIR", dir)
+    db, err := Open(memoryConfig)
+    require.NoError(t, err)
+    require.NoError(t, RunMigrations(db))
+    var version int
+    require.NoError(t, db.Raw("SELECT version FROM schema_migrations").Scan(&version).Error)
+    assert.Equal(t, 1, version)
+}
+
+// TestMigrator проверяет пошаговое применение, откат и принудительную версию
+func TestMigrator(t *testing.T) {
+    db, err := Open(memoryConfig)
+    require.NoError(t, err)
+    m, err := NewMigrator(db)
+    require.NoError(t, err)
+    defer m.Close()
+    version, dirty, err := m.Version()
+    require.NoError(t, err)
+    assert.Equal(t, uint(0), version)
+    assert.False(t, dirty)
+
+    require.NoError(t, m.Up(2))
+    version, _, err = m.Version()
+    require.NoError(t, err)
+    assert.Equal(t, uint(2), version)
+
+    require.NoError(t, m.Up(0))
+    require.NoError(t, m.Down(1))
+    version, _, err = m.Version()
+    require.NoError(t, err)
+    assert.Equal(t, latestVersion(t, DriverSQLite)-1, version)
+
+    // Запрошено больше миграций, чем применено: откатываются все
+    require.NoError(t, m.Down(1000))
+    version, _, err = m.Version()
+    require.NoError(t, err)
+    assert.Equal(t, uint(0), version)
+    assert.False(t, db.Migrator().HasTable("people"))
+
+    require.NoError(t, m.Force(3))
+    version, dirty, err = m.Version()
+    require.NoError(t, err)
+    assert.Equal(t, uint(3), version)
+    assert.False(t, dirty)
+}
+
+// TestPublicIDMigration проверяет заполнение публичных идентификаторов у существующих людей
+func TestPublicIDMigration(t *testing.T) {
+    db, err := Open(memoryConfig)
+    require.NoError(t, err)
+    m, err := NewMigrator(db)
+    require.NoError(t, err)
+    defer m.Close()
+    require.NoError(t, m.Up(9))
+    require.NoError(t, db.Exec(`INSERT INTO people (name, surname, created_at) VALUES
+        ('Дмитрий', 'Ушаков', '2025-01-02 03:04:05.678+00:00'), ('Иван', 'Петров', '2025-01-02 03:04:05+00:00')`).Error)
+    require.NoError(t, db.Exec(`INSERT INTO person_versions (person_id, version, operation, snapshot)
+        VALUES (1, 1, 'create', '{"id":1,"name":"Дмитрий"}')`).Error)
+
+    require.NoError(t, m.Up(1))
+    var ids []string
+    require.NoError(t, db.Raw("SELECT public_id FROM people ORDER BY id").Scan(&ids).Error)
+    require.Len(t, ids, 2)
+    // Время создания 2025-01-02T03:04:05.678Z — 0x19424f8632e миллисекунд
+    assert.Regexp(t, `^019424f8-632e-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, ids[0])
+    assert.Regexp(t, `^019424f8-6088-7`, ids[1])
+    assert.NotEqual(t, ids[0][15:], ids[1][15:])
+    var snapshot string
+    require.NoError(t, db.Raw("SELECT snapshot FROM person_versions").Scan(&snapshot).Error)
+    assert.JSONEq(t, `{"id":"`+ids[0]+`","name":"Дмитрий"}`, snapshot)
+
+    require.NoError(t, m.Down(1))
+    require.NoError(t, db.Raw("SELECT snapshot FROM person_versions").Scan(&snapshot).Error)
+    assert.JSONEq(t, `{"id":1,"name":"Дмитрий"}`, snapshot)
+}
+
+// TestCreateMigration проверяет нумерацию новых миграций для всех драйверов
+func TestCreateMigration(t *testing.T) {
+    dir := t.TempDir()
+    for _, driver := range []string{DriverPostgres, DriverSQLite} {
+        require.NoError(t, os.Mkdir(filepath.Join(dir, driver), 0o755))
+    }
+    require.NoError(t, os.WriteFile(filepath.Join(dir, DriverPostgres, "007_existing.up.sql"), nil, 0o644))
+    files, err := CreateMigration(dir, "add_column")
+    require.NoError(t, err)
+    assert.Equal(t, []string{
+        filepath.Join(dir, DriverPostgres, "008_add_column.up.sql"),
+        filepath.Join(dir, DriverPostgres, "008_add_column.down.sql"),
+        filepath.Join(dir, DriverSQLite, "008_add_column.up.sql"),
+        filepath.Join(dir, DriverSQLite, "008_add_column.down.sql"),
+    }, files)
+    _, err = CreateMigration(dir, "Add Column")
+    assert.Error(t, err)
+}
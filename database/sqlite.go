@@ -0,0 +1,19 @@
+package database
+
+import (
+    "database/sql"
+    "strings"
+    "github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver драйвер SQLite, в котором lower() понимает не только ASCII,
+// чтобы поиск без учёта регистра работал для кириллицы так же, как ILIKE в PostgreSQL
+const sqliteDriver = "sqlite3_unicode"
+
+func init() {
+    sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+        ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+            return conn.RegisterFunc("lower", strings.ToLower, true)
+        },
+    })
+}
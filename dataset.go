@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"person-api/enrichment"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// runDataset выполняет подкоманду dataset:
+//
+//	person-api dataset import [-out ПУТЬ] [-format csv|json] ИСТОЧНИК
+//
+// ИСТОЧНИК — файл CSV/JSON, URL (http/https) или embedded. Справочник проверяется
+// и атомарно записывается в -out (по умолчанию ENRICHMENT_DATASET). Запущенный сервер
+// подхватывает новый файл через POST /admin/enrichment/dataset/reload.
+func runDataset(args []string) error {
+	if len(args) == 0 || args[0] != "import" {
+		return fmt.Errorf("использование: dataset import [-out ПУТЬ] [-format csv|json] ИСТОЧНИК")
+	}
+	fs := flag.NewFlagSet("dataset import", flag.ContinueOnError)
+	out := fs.String("out", os.Getenv("ENRICHMENT_DATASET"), "куда записать справочник (CSV или JSON по расширению)")
+	format := fs.String("format", "", "формат источника: csv или json (по умолчанию по расширению)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("нужен ровно один источник, получено %d", fs.NArg())
+	}
+	if *out == "" || *out == enrichment.EmbeddedDataset {
+		return fmt.Errorf("не задан путь для записи: укажите -out или ENRICHMENT_DATASET")
+	}
+	source := fs.Arg(0)
+	ds, err := readDataset(source, *format)
+	if err != nil {
+		return err
+	}
+	if err := writeDatasetFile(*out, ds); err != nil {
+		return err
+	}
+	logrus.Infof("Справочник импортирован: %s → %s, имён: %d", source, *out, ds.Len())
+	return nil
+}
+
+// readDataset загружает справочник из файла, по URL или встроенный
+func readDataset(source, format string) (*enrichment.Dataset, error) {
+	if source == enrichment.EmbeddedDataset {
+		return enrichment.LoadDataset(source)
+	}
+	if format == "" {
+		format = enrichment.DatasetFormat(source)
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return enrichment.ParseDataset(f, format)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("загрузка %s: статус %d", source, resp.StatusCode)
+	}
+	return enrichment.ParseDataset(io.LimitReader(resp.Body, 256<<20), format)
+}
+
+// writeDatasetFile записывает справочник во временный файл рядом с path и переименовывает,
+// чтобы сервер никогда не прочитал файл наполовину
+func writeDatasetFile(path string, ds *enrichment.Dataset) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dataset-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := enrichment.WriteDataset(tmp, ds, enrichment.DatasetFormat(path)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -15,9 +15,264 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/enrichment/cache": {
+            "get": {
+                "description": "Возвращает количество попаданий, промахов и записей в памяти",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика кэша обогащения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.CacheStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет записи кэша для имени у всех провайдеров или весь кэш, если имя не указано",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очистка кэша обогащения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CachePurgeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/dataset": {
+            "get": {
+                "description": "Возвращает источник, количество имён и время загрузки офлайн-справочника",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Состояние справочника имён",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.DatasetStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/dataset/reload": {
+            "post": {
+                "description": "Заново загружает справочник из ENRICHMENT_DATASET (например, после dataset import).\nПри ошибке продолжает работать прежний справочник.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Перечитать справочник имён",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.DatasetStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/enrichment/quota": {
+            "get": {
+                "description": "Возвращает лимиты из заголовков X-Rate-Limit-* последних ответов провайдеров",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Квоты провайдеров обогащения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/enrichment.QuotaStatus"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/jobs/{id}": {
+            "get": {
+                "description": "Возвращает прогресс фоновой задачи переобогащения. Завершённые задачи хранятся час, затем 404.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Статус массового переобогащения",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.JobStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Проверяет базу данных и возвращает состояние circuit breaker провайдеров обогащения.\nОткрытый circuit breaker переводит статус в degraded, недоступная база — в down (503).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Состояние сервиса",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/people": {
             "get": {
-                "description": "Возвращает список людей с фильтрацией и пагинацией",
+                "description": "Возвращает список людей с фильтрацией и пагинацией. ETag и Last-Modified вычисляются\nпо фильтру и времени последнего изменения подходящих людей; с If-None-Match или\nIf-Modified-Since неизменившийся список не передаётся повторно (304).",
                 "produces": [
                     "application/json"
                 ],
@@ -57,16 +312,45 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
+                        "minimum": 0,
                         "type": "integer",
+                        "default": 0,
                         "description": "Смещение (пагинация)",
                         "name": "skip",
                         "in": "query"
                     },
                     {
+                        "maximum": 100,
+                        "minimum": 1,
                         "type": "integer",
+                        "default": 10,
                         "description": "Ограничение (пагинация)",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Включая удалённых (только с токеном администратора)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (для include_deleted)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сохранённой копии",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified сохранённой копии",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -77,6 +361,57 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.Person"
                             }
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Директивы кэширования (CACHE_CONTROL_PEOPLE)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия списка"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения подходящих людей"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество записей по фильтру без учёта пагинации"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Копия клиента актуальна",
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Директивы кэширования (CACHE_CONTROL_PEOPLE)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия списка"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения подходящих людей"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество записей по фильтру без учёта пагинации"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -88,7 +423,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Принимает имя, фамилию и отчество. Определяет пол и национальность с помощью внешних API.",
+                "description": "Принимает имя, фамилию и отчество. Определяет пол, национальность и возраст с помощью внешних API.\nВозраст, пол и национальность можно передать сразу: такие поля не обогащаются (источник client).\nВ асинхронном режиме запись сохраняется сразу со статусом обогащения pending.",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,7 +449,491 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Person"
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/batch": {
+            "post": {
+                "description": "Принимает массив людей (не больше 100) и создаёт их одной транзакцией.\nИмена отправляются провайдерам пакетными запросами по 10 штук.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Создание нескольких людей",
+                "parameters": [
+                    {
+                        "description": "Данные для создания",
+                        "name": "people",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.PersonCreate"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/enrich": {
+            "post": {
+                "description": "Запускает фоновую задачу переобогащения людей, отобранных теми же фильтрами, что и GET /people.\nskip и limit применяются, только если указаны явно. Прогресс — GET /enrichment/jobs/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Массовое переобогащение",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по имени",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по фамилии",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Фильтр по возрасту",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по полу",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр по национальности",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Смещение (пагинация)",
+                        "name": "skip",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Ограничение (пагинация)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Провайдеры (genderize, nationalize, agify)",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.JobStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}": {
+            "get": {
+                "description": "Возвращает информацию о человеке по его ID. С include=enrichment добавляет ответы провайдеров\nс вероятностью, размером выборки и временем запроса. С as_of возвращает состояние на указанный момент\nпо истории изменений.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Получить человека по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дополнительные данные: enrichment — происхождение обогащённых полей",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени в формате RFC 3339, например 2025-01-01T12:00:00Z",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сохранённой копии",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified сохранённой копии",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Директивы кэширования (CACHE_CONTROL_PERSON)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия записи, с include=enrichment — и записей о происхождении (кроме ответов с as_of)"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения (кроме ответов с as_of и include=enrichment)"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Копия клиента актуальна",
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Директивы кэширования (CACHE_CONTROL_PERSON)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия записи, с include=enrichment — и записей о происхождении (кроме ответов с as_of)"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Время последнего изменения (кроме ответов с as_of и include=enrichment)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет существующего человека по ID. Принимает только те поля, которые нужно изменить.\nС заголовком If-Match (ETag из GET /people/{id}) изменение применяется, только если запись\nне менялась; иначе 412. Параллельное изменение между чтением и записью тоже даёт 412.\nСмена имени сбрасывает пол, национальность и возраст, не указанные клиентом,\nи ставит человека в очередь фонового обогащения (статус pending).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Обновить данные человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag записи",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Обновляемые данные",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PersonUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Помечает человека удалённым. Администратор может восстановить его через POST /people/{id}/restore,\nпока не истёк срок хранения удалённых (PEOPLE_RETENTION).\nС заголовком If-Match удаляет, только если запись не менялась; иначе 412.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Удалить человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag записи",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}/enrich": {
+            "post": {
+                "description": "Заново запускает обогащение в обход кэша и сохраняет обновлённые данные.\nПараметр provider (можно несколько) ограничивает набор провайдеров.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Переобогатить человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Провайдеры (genderize, nationalize, agify)",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}/enrichment": {
+            "get": {
+                "description": "Возвращает статус фонового обогащения, количество попыток и последнюю ошибку",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Статус обогащения человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EnrichmentStatus"
                         }
                     },
                     "400": {
@@ -123,6 +942,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -132,20 +957,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/people/{id}": {
+        "/people/{id}/history": {
             "get": {
-                "description": "Возвращает информацию о человеке по его ID",
+                "description": "Возвращает версии человека по возрастанию номера: операцию, автора (X-Actor),\nснимок после изменения и изменённые поля со значениями до и после.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "people"
                 ],
-                "summary": "Получить человека по ID",
+                "summary": "История изменений человека",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID человека",
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -155,7 +980,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Person"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PersonVersion"
+                            }
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Директивы кэширования (CACHE_CONTROL_HISTORY)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -163,37 +1003,40 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновляет существующего человека по ID. Принимает только те поля, которые нужно изменить.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/people/{id}/restore": {
+            "post": {
+                "description": "Снимает отметку об удалении, если запись ещё не очищена по сроку хранения",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "people"
                 ],
-                "summary": "Обновить данные человека",
+                "summary": "Восстановить удалённого человека",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID человека",
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Обновляемые данные",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.PersonUpdate"
-                        }
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -209,6 +1052,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -222,30 +1071,51 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет человека по ID",
+            }
+        },
+        "/people/{id}/revert/{version}": {
+            "post": {
+                "description": "Возвращает имя, фамилию, отчество, возраст, пол и национальность к состоянию указанной версии.\nВозврат записывается в историю новой версией, поэтому его тоже можно отменить.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "people"
                 ],
-                "summary": "Удалить человека",
+                "summary": "Вернуть человека к версии",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID человека",
+                        "type": "string",
+                        "description": "UUID человека (на переходный период также числовой ID)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Кто выполняет возврат",
+                        "name": "X-Actor",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponse"
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -253,12 +1123,198 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         }
     },
     "definitions": {
+        "enrichment.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "Ошибок подряд",
+                    "type": "integer",
+                    "example": 0
+                },
+                "opened_at": {
+                    "description": "Время размыкания",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Имя провайдера",
+                    "type": "string",
+                    "example": "genderize"
+                },
+                "state": {
+                    "description": "Состояние",
+                    "type": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "enrichment.CacheStats": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "Записей в памяти",
+                    "type": "integer",
+                    "example": 42
+                },
+                "hits": {
+                    "description": "Попадания",
+                    "type": "integer",
+                    "example": 120
+                },
+                "misses": {
+                    "description": "Промахи",
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "enrichment.DatasetStatus": {
+            "type": "object",
+            "properties": {
+                "loaded_at": {
+                    "description": "Время загрузки",
+                    "type": "string"
+                },
+                "names": {
+                    "description": "Количество имён",
+                    "type": "integer",
+                    "example": 40
+                },
+                "source": {
+                    "description": "Источник",
+                    "type": "string",
+                    "example": "embedded"
+                }
+            }
+        },
+        "enrichment.JobStatus": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "С ошибками",
+                    "type": "integer",
+                    "example": 2
+                },
+                "finished_at": {
+                    "description": "Время завершения",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Идентификатор задачи",
+                    "type": "string",
+                    "example": "9f8c2a1b4d6e7f00"
+                },
+                "last_error": {
+                    "description": "Последняя ошибка",
+                    "type": "string",
+                    "example": "таймаут"
+                },
+                "processed": {
+                    "description": "Обработано",
+                    "type": "integer",
+                    "example": 40
+                },
+                "providers": {
+                    "description": "Провайдеры (пусто — все)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "genderize"
+                    ]
+                },
+                "started_at": {
+                    "description": "Время запуска",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус задачи",
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "description": "Всего людей",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "enrichment.QuotaStatus": {
+            "type": "object",
+            "properties": {
+                "exhausted": {
+                    "description": "Квота исчерпана",
+                    "type": "boolean"
+                },
+                "known": {
+                    "description": "Получены ли заголовки квоты",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "description": "Лимит запросов за период",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "provider": {
+                    "description": "Имя провайдера",
+                    "type": "string",
+                    "example": "genderize"
+                },
+                "remaining": {
+                    "description": "Осталось запросов",
+                    "type": "integer",
+                    "example": 250
+                },
+                "reset_at": {
+                    "description": "Время восстановления квоты",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CachePurgeResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "description": "Количество удалённых записей",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handlers.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "description": "Состояние базы данных",
+                    "type": "string",
+                    "example": "ok"
+                },
+                "providers": {
+                    "description": "Circuit breaker провайдеров",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/enrichment.BreakerStatus"
+                    }
+                },
+                "status": {
+                    "description": "ok, degraded или down",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "handlers.PersonCreate": {
             "type": "object",
             "required": [
@@ -266,10 +1322,31 @@ const docTemplate = `{
                 "surname"
             ],
             "properties": {
+                "age": {
+                    "description": "Возраст (опционально)",
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 42
+                },
+                "gender": {
+                    "description": "Пол (опционально)",
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ],
+                    "example": "male"
+                },
                 "name": {
                     "description": "Имя (обязательное)",
                     "type": "string"
                 },
+                "nationality": {
+                    "description": "Код страны (опционально)",
+                    "type": "string",
+                    "example": "RU"
+                },
                 "patronymic": {
                     "description": "Отчество (опционально)",
                     "type": "string"
@@ -285,19 +1362,29 @@ const docTemplate = `{
             "properties": {
                 "age": {
                     "description": "Возраст (опционально)",
-                    "type": "integer"
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 42
                 },
                 "gender": {
                     "description": "Пол (опционально)",
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        ""
+                    ],
+                    "example": "male"
                 },
                 "name": {
                     "description": "Имя (опционально)",
                     "type": "string"
                 },
                 "nationality": {
-                    "description": "Национальность (опционально)",
-                    "type": "string"
+                    "description": "Код страны (опционально)",
+                    "type": "string",
+                    "example": "RU"
                 },
                 "patronymic": {
                     "description": "Отчество (опционально)",
@@ -309,6 +1396,35 @@ const docTemplate = `{
                 }
             }
         },
+        "models.EnrichmentStatus": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Количество попыток",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "Текст последней ошибки",
+                    "type": "string",
+                    "example": "таймаут"
+                },
+                "person_id": {
+                    "description": "ID человека",
+                    "type": "string",
+                    "example": "01912d68-783e-7a03-8f4e-2c5b9a3d7e10"
+                },
+                "status": {
+                    "description": "Статус обогащения",
+                    "type": "string",
+                    "example": "pending"
+                },
+                "updated_at": {
+                    "description": "Время последнего изменения",
+                    "type": "string"
+                }
+            }
+        },
         "models.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -318,6 +1434,13 @@ const docTemplate = `{
                 }
             }
         },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "new": {},
+                "old": {}
+            }
+        },
         "models.MessageResponse": {
             "type": "object",
             "properties": {
@@ -334,13 +1457,44 @@ const docTemplate = `{
                     "description": "Возраст (опционально)",
                     "type": "integer"
                 },
+                "age_source": {
+                    "description": "Источники значений: client или provider (пусто — неизвестно, для старых записей)",
+                    "type": "string",
+                    "example": "client"
+                },
+                "created_at": {
+                    "description": "Время создания и последнего изменения",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Время удаления: удалённые записи скрыты и окончательно стираются по истечении срока хранения",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "enrichment": {
+                    "description": "Происхождение обогащённых данных (только при ?include=enrichment)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PersonEnrichment"
+                    }
+                },
+                "enrichment_status": {
+                    "description": "Статус обогащения: pending, done или failed",
+                    "type": "string"
+                },
                 "gender": {
                     "description": "Пол (опционально)",
                     "type": "string"
                 },
+                "gender_source": {
+                    "type": "string",
+                    "example": "provider"
+                },
                 "id": {
-                    "description": "Уникальный идентификатор",
-                    "type": "integer"
+                    "description": "Публичный идентификатор (UUID v7)",
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "01912d68-783e-7a03-8f4e-2c5b9a3d7e10"
                 },
                 "name": {
                     "description": "Имя (обязательное)",
@@ -350,6 +1504,10 @@ const docTemplate = `{
                     "description": "Национальность (опционально)",
                     "type": "string"
                 },
+                "nationality_source": {
+                    "type": "string",
+                    "example": "provider"
+                },
                 "patronymic": {
                     "description": "Отчество (опционально)",
                     "type": "string"
@@ -357,6 +1515,110 @@ const docTemplate = `{
                 "surname": {
                     "description": "Фамилия (обязательная)",
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Номер версии записи: растёт при каждом изменении, отдаётся в ETag",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.PersonEnrichment": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Прошло ли значение порог",
+                    "type": "boolean"
+                },
+                "cached": {
+                    "description": "Взято из кэша",
+                    "type": "boolean"
+                },
+                "count": {
+                    "description": "Размер выборки",
+                    "type": "integer",
+                    "example": 1250
+                },
+                "field": {
+                    "description": "Обогащаемое поле",
+                    "type": "string",
+                    "example": "gender"
+                },
+                "hint": {
+                    "description": "Подсказка, переданная провайдеру",
+                    "type": "string",
+                    "example": "country_id=RU"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор",
+                    "type": "integer"
+                },
+                "looked_up_at": {
+                    "description": "Время запроса к провайдеру",
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "probability": {
+                    "description": "Вероятность",
+                    "type": "number",
+                    "example": 0.98
+                },
+                "provider": {
+                    "description": "Имя провайдера",
+                    "type": "string",
+                    "example": "genderize"
+                },
+                "summary": {
+                    "description": "Сводка ответа провайдера",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Предложенное значение",
+                    "type": "string",
+                    "example": "male"
+                }
+            }
+        },
+        "models.PersonVersion": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Кто изменил (заголовок X-Actor)",
+                    "type": "string",
+                    "example": "support:ivanov"
+                },
+                "changes": {
+                    "description": "Изменённые поля",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "created_at": {
+                    "description": "Время изменения",
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "operation": {
+                    "description": "Операция",
+                    "type": "string",
+                    "example": "update"
+                },
+                "snapshot": {
+                    "description": "Состояние после операции",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "Номер версии (с 1)",
+                    "type": "integer",
+                    "example": 2
                 }
             }
         }
@@ -365,12 +1627,12 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
-	BasePath:         "",
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Person API",
+	Description:      "REST API для управления данными людей",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,66 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"person-api/models"
+	"strconv"
+)
+
+// AgifyURL адрес API Agify.io по умолчанию
+const AgifyURL = "https://api.agify.io"
+
+// AgifyResponse структура ответа от Agify.io
+type AgifyResponse struct {
+	Name  string `json:"name"`  // Запрошенное имя
+	Age   *int   `json:"age"`   // Возраст (null, если имя неизвестно)
+	Count int    `json:"count"` // Размер выборки
+}
+
+func (r AgifyResponse) requestedName() string {
+	return r.Name
+}
+
+// Agify определяет возраст по имени через Agify.io
+type Agify struct {
+	*Upstream
+}
+
+// NewAgify создаёт провайдер Agify.io
+func NewAgify(client *http.Client, cfg ProviderConfig) *Agify {
+	return &Agify{newUpstream("agify", client, cfg)}
+}
+
+// Fields возвращает заполняемые поля
+func (a *Agify) Fields() []string {
+	return []string{"age"}
+}
+
+// Enrich заполняет возраст, если ответ основан на достаточной выборке
+func (a *Agify) Enrich(ctx context.Context, person *models.Person) error {
+	var resp AgifyResponse
+	if err := a.getJSON(ctx, url.Values{"name": {person.Name}}, &resp); err != nil {
+		return err
+	}
+	a.apply(person, resp)
+	return nil
+}
+
+// EnrichBatch определяет возраст для нескольких людей пачками по BatchSize имён
+func (a *Agify) EnrichBatch(ctx context.Context, people []*models.Person) error {
+	return lookupBatch(ctx, a.Upstream, people, nil, a.apply)
+}
+
+// apply переносит ответ провайдера в person
+func (a *Agify) apply(person *models.Person, resp AgifyResponse) {
+	accepted := resp.Age != nil && float64(resp.Count) >= a.Config.Threshold
+	value := ""
+	if resp.Age != nil {
+		value = strconv.Itoa(*resp.Age)
+	}
+	if accepted {
+		person.Age = resp.Age
+	}
+	record(person, a.Name(), "age", value, nil, &resp.Count, accepted, resp)
+}
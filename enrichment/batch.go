@@ -0,0 +1,108 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"person-api/models"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchSize максимум имён в одном пакетном запросе (name[]=a&name[]=b...)
+const BatchSize = 10
+
+// batchConcurrency сколько пакетных запросов к одному провайдеру выполняется одновременно
+const batchConcurrency = 4
+
+// BatchEnricher провайдер, умеющий обогащать несколько человек за один запрос
+type BatchEnricher interface {
+	Enricher
+	EnrichBatch(ctx context.Context, people []*models.Person) error
+}
+
+// batchResponse элемент ответа пакетного запроса: провайдер возвращает запрошенное имя
+type batchResponse interface {
+	requestedName() string
+}
+
+// lookupBatch группирует людей по нормализованному имени, запрашивает уникальные имена
+// пачками по BatchSize и раздаёт ответы всем людям с этим именем.
+// params возвращает дополнительные параметры запроса для человека (может быть nil):
+// люди с разными параметрами попадают в разные пачки.
+// Ответы сопоставляются по возвращённому имени, а не по позиции: имя, которого нет
+// в ответе, остаётся без данных (и без записи о происхождении).
+func lookupBatch[T batchResponse](ctx context.Context, u *Upstream, people []*models.Person, params func(*models.Person) url.Values, apply func(*models.Person, T)) error {
+	type group struct {
+		params url.Values
+		names  []string
+		people map[string][]*models.Person
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, p := range people {
+		extra := url.Values{}
+		if params != nil {
+			extra = params(p)
+		}
+		gk := extra.Encode()
+		grp, ok := groups[gk]
+		if !ok {
+			grp = &group{params: extra, people: make(map[string][]*models.Person)}
+			groups[gk] = grp
+			order = append(order, gk)
+		}
+		key := NormalizeName(p.Name)
+		if _, ok := grp.people[key]; !ok {
+			grp.names = append(grp.names, p.Name)
+		}
+		grp.people[key] = append(grp.people[key], p)
+	}
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	var g errgroup.Group
+	g.SetLimit(batchConcurrency)
+	for _, gk := range order {
+		grp := groups[gk]
+		for start := 0; start < len(grp.names); start += BatchSize {
+			chunk := grp.names[start:min(start+BatchSize, len(grp.names))]
+			g.Go(func() error {
+				query := url.Values{"name[]": chunk}
+				for k, v := range grp.params {
+					query[k] = v
+				}
+				var resp []T
+				err := u.getJSON(ctx, query, &resp)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return nil
+				}
+				answered := make(map[string]bool, len(resp))
+				for _, r := range resp {
+					key := NormalizeName(r.requestedName())
+					if answered[key] {
+						continue
+					}
+					answered[key] = true
+					for _, p := range grp.people[key] {
+						apply(p, r)
+					}
+				}
+				for _, name := range chunk {
+					if !answered[NormalizeName(name)] {
+						logrus.Warnf("%s: нет ответа для имени %q", u.name, name)
+					}
+				}
+				return nil
+			})
+		}
+	}
+	g.Wait()
+	return errors.Join(errs...)
+}
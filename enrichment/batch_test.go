@@ -0,0 +1,97 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"person-api/models"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenderizeBatch проверяет группировку имён в пакетные запросы и раздачу ответов
+func TestGenderizeBatch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		names := r.URL.Query()["name[]"]
+		assert.LessOrEqual(t, len(names), BatchSize)
+		resp := make([]GenderizeResponse, len(names))
+		for i, name := range names {
+			resp[i] = GenderizeResponse{Name: name, Gender: "female", Probability: 0.9, Count: 10}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+
+	// 12 человек, из них два с одним и тем же именем в разном регистре — 11 уникальных имён
+	var people []*models.Person
+	for i := 0; i < 11; i++ {
+		people = append(people, &models.Person{Name: fmt.Sprintf("Name%d", i)})
+	}
+	people = append(people, &models.Person{Name: "NAME0"})
+	assert.NoError(t, g.EnrichBatch(context.Background(), people))
+	assert.EqualValues(t, 2, requests.Load())
+	for _, p := range people {
+		assert.Equal(t, "female", p.Gender, p.Name)
+		assert.Len(t, p.Enrichments, 1)
+	}
+}
+
+// TestGenderizeBatchMatchesByName проверяет, что ответы сопоставляются по имени:
+// переставленный и неполный ответ не переносит пол одного человека другому
+func TestGenderizeBatchMatchesByName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]GenderizeResponse{
+			{Name: "МАРИЯ", Gender: "female", Probability: 0.99},
+			{Name: "Иван", Gender: "male", Probability: 0.99},
+		})
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+	cache := NewNameCache(10, 0, nil)
+	people := []*models.Person{{Name: "Иван"}, {Name: "Пётр"}, {Name: "Мария"}}
+	assert.NoError(t, cache.Wrap(g).(BatchEnricher).EnrichBatch(context.Background(), people))
+	assert.Equal(t, "male", people[0].Gender)
+	assert.Empty(t, people[1].Gender)
+	assert.Empty(t, people[1].Enrichments)
+	assert.Equal(t, "female", people[2].Gender)
+	// Имя без ответа не кэшируется как «нет данных»
+	_, ok := cache.Get(context.Background(), "genderize", "Пётр", "")
+	assert.False(t, ok)
+}
+
+// TestRegistryEnrichBatch проверяет пакетное обогащение через кэш: повторные имена не запрашиваются
+func TestRegistryEnrichBatch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		names := r.URL.Query()["name[]"]
+		resp := make([]GenderizeResponse, len(names))
+		for i, name := range names {
+			resp[i] = GenderizeResponse{Name: name, Gender: "male", Probability: 0.99}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+	cache := NewNameCache(100, 0, nil)
+	registry := NewRegistry(cache.Wrap(NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})))
+
+	people := []*models.Person{{Name: "Иван"}, {Name: "Пётр"}}
+	assert.NoError(t, registry.EnrichBatch(context.Background(), people))
+	assert.Equal(t, "male", people[0].Gender)
+	assert.Equal(t, "male", people[1].Gender)
+	assert.EqualValues(t, 1, requests.Load())
+
+	// Оба имени уже в кэше — провайдер не вызывается
+	people = []*models.Person{{Name: "иван"}, {Name: "пётр"}}
+	assert.NoError(t, registry.EnrichBatch(context.Background(), people))
+	assert.Equal(t, "male", people[1].Gender)
+	assert.EqualValues(t, 1, requests.Load())
+}
@@ -0,0 +1,108 @@
+package enrichment
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen провайдер временно отключён после серии ошибок
+var ErrCircuitOpen = errors.New("circuit breaker открыт")
+
+// Состояния circuit breaker
+const (
+	BreakerClosed   = "closed"    // Запросы проходят
+	BreakerOpen     = "open"      // Запросы отклоняются без обращения к провайдеру
+	BreakerHalfOpen = "half-open" // Пропускается один пробный запрос
+)
+
+// BreakerStatus состояние circuit breaker провайдера для health-эндпоинта
+type BreakerStatus struct {
+	Provider string     `json:"provider" example:"genderize"` // Имя провайдера
+	State    string     `json:"state" example:"closed"`       // Состояние
+	Failures int        `json:"failures" example:"0"`         // Ошибок подряд
+	OpenedAt *time.Time `json:"opened_at,omitempty"`          // Время размыкания
+}
+
+// CircuitBreaker размыкается после threshold ошибок подряд и через cooldown
+// пропускает пробный запрос: успех замыкает его, ошибка — снова размыкает
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker создаёт circuit breaker (threshold <= 0 — никогда не размыкается)
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow сообщает, можно ли выполнить запрос
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state() {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// Success отмечает успешный запрос
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// Failure отмечает неудачный запрос
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// Release снимает отметку пробного запроса, не меняя состояния: запрос прерван
+// снаружи и ничего не говорит о здоровье провайдера, следующий запрос снова станет пробным
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Status возвращает снимок состояния
+func (b *CircuitBreaker) Status(provider string) BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{Provider: provider, State: b.state(), Failures: b.failures}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// state вычисляет текущее состояние; вызывается под мьютексом
+func (b *CircuitBreaker) state() string {
+	if b.openedAt.IsZero() {
+		return BreakerClosed
+	}
+	if time.Since(b.openedAt) < b.cooldown {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
@@ -0,0 +1,288 @@
+package enrichment
+
+import (
+	"container/list"
+	"context"
+	"person-api/models"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CacheEntry результат провайдера для одного имени.
+// Пустые поля означают, что провайдер не дал уверенного ответа — это тоже кэшируется.
+type CacheEntry struct {
+	Provider    string    // Имя провайдера
+	Name        string    // Нормализованное имя
+	Hint        string    // Подсказка, с которой запрашивался провайдер (например, country_id=RU)
+	Gender      string    // Пол
+	Nationality string    // Национальность
+	Age         *int      // Возраст
+	StoredAt    time.Time // Время сохранения
+	// Записи о происхождении, полученные при исходном запросе
+	Records []models.PersonEnrichment
+}
+
+// CacheStore долговременное хранилище кэша (например, таблица в Postgres)
+type CacheStore interface {
+	Load(ctx context.Context, provider, name, hint string) (CacheEntry, bool, error)
+	Save(ctx context.Context, entry CacheEntry) error
+	// Delete удаляет записи по имени или все записи, если name пустое
+	Delete(ctx context.Context, name string) (int64, error)
+}
+
+// CacheStats счётчики кэша обогащения
+type CacheStats struct {
+	Hits    int64 `json:"hits" example:"120"`   // Попадания
+	Misses  int64 `json:"misses" example:"15"`  // Промахи
+	Entries int   `json:"entries" example:"42"` // Записей в памяти
+}
+
+// NameCache кэш результатов обогащения по нормализованному имени:
+// LRU в памяти с TTL и опциональное долговременное хранилище за ним
+type NameCache struct {
+	mu     sync.Mutex
+	size   int
+	ttl    time.Duration
+	order  *list.List
+	items  map[string]*list.Element
+	store  CacheStore
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewNameCache создаёт кэш на size записей; store может быть nil
+func NewNameCache(size int, ttl time.Duration, store CacheStore) *NameCache {
+	return &NameCache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+		store: store,
+	}
+}
+
+// NormalizeName приводит имя к ключу кэша
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func cacheKey(provider, name, hint string) string {
+	return provider + ":" + name + ":" + hint
+}
+
+// Get ищет результат провайдера для имени и подсказки сначала в памяти, затем в хранилище
+func (c *NameCache) Get(ctx context.Context, provider, name, hint string) (CacheEntry, bool) {
+	name = NormalizeName(name)
+	if entry, ok := c.getLocal(provider, name, hint); ok {
+		c.hits.Add(1)
+		return entry, true
+	}
+	if c.store != nil {
+		entry, ok, err := c.store.Load(ctx, provider, name, hint)
+		if err != nil {
+			logrus.Warnf("Ошибка чтения кэша обогащения: %v", err)
+		} else if ok && !c.expired(entry) {
+			c.setLocal(entry)
+			c.hits.Add(1)
+			return entry, true
+		}
+	}
+	c.misses.Add(1)
+	return CacheEntry{}, false
+}
+
+// Set сохраняет результат провайдера в памяти и в хранилище
+func (c *NameCache) Set(ctx context.Context, entry CacheEntry) {
+	entry.Name = NormalizeName(entry.Name)
+	if entry.StoredAt.IsZero() {
+		entry.StoredAt = time.Now()
+	}
+	c.setLocal(entry)
+	if c.store != nil {
+		if err := c.store.Save(ctx, entry); err != nil {
+			logrus.Warnf("Ошибка записи кэша обогащения: %v", err)
+		}
+	}
+}
+
+// Purge удаляет записи для имени у всех провайдеров и со всеми подсказками (или весь кэш, если name пустое)
+// и возвращает количество удалённых записей
+func (c *NameCache) Purge(ctx context.Context, name string) (int64, error) {
+	name = NormalizeName(name)
+	c.mu.Lock()
+	var purged int64
+	for key, el := range c.items {
+		if name == "" || el.Value.(CacheEntry).Name == name {
+			c.order.Remove(el)
+			delete(c.items, key)
+			purged++
+		}
+	}
+	c.mu.Unlock()
+	if c.store != nil {
+		stored, err := c.store.Delete(ctx, name)
+		if err != nil {
+			return purged, err
+		}
+		// Записи в памяти — подмножество хранилища
+		if stored > purged {
+			purged = stored
+		}
+	}
+	return purged, nil
+}
+
+// Stats возвращает счётчики кэша
+func (c *NameCache) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+}
+
+func (c *NameCache) expired(entry CacheEntry) bool {
+	return c.ttl > 0 && time.Since(entry.StoredAt) > c.ttl
+}
+
+func (c *NameCache) getLocal(provider, name, hint string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[cacheKey(provider, name, hint)]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	entry := el.Value.(CacheEntry)
+	if c.expired(entry) {
+		c.order.Remove(el)
+		delete(c.items, cacheKey(provider, name, hint))
+		return CacheEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return entry, true
+}
+
+func (c *NameCache) setLocal(entry CacheEntry) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cacheKey(entry.Provider, entry.Name, entry.Hint)
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(entry)
+	// Вытесняем давно не использованные записи
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		old := oldest.Value.(CacheEntry)
+		delete(c.items, cacheKey(old.Provider, old.Name, old.Hint))
+	}
+}
+
+// Wrap оборачивает провайдера кэшем: повторные запросы по тому же имени не уходят во внешний API
+func (c *NameCache) Wrap(e Enricher) Enricher {
+	return &cachedEnricher{Enricher: e, cache: c}
+}
+
+// cachedEnricher провайдер с кэшем перед ним
+type cachedEnricher struct {
+	Enricher
+	cache *NameCache
+}
+
+// Unwrap возвращает провайдера без кэша
+func (e *cachedEnricher) Unwrap() Enricher {
+	return e.Enricher
+}
+
+// Enrich берёт результат из кэша или запрашивает провайдера и запоминает ответ
+func (e *cachedEnricher) Enrich(ctx context.Context, person *models.Person) error {
+	return e.EnrichBatch(ctx, []*models.Person{person})
+}
+
+// EnrichBatch отвечает из кэша, а промахи отправляет провайдеру одним пакетом
+func (e *cachedEnricher) EnrichBatch(ctx context.Context, people []*models.Person) error {
+	var misses []*models.Person
+	for _, person := range people {
+		if !isRefresh(ctx) {
+			if entry, ok := e.cache.Get(ctx, e.Name(), person.Name, hintFor(e.Enricher, person)); ok {
+				applyCacheEntry(person, entry)
+				continue
+			}
+		}
+		misses = append(misses, person)
+	}
+	if len(misses) == 0 {
+		return nil
+	}
+	before := make([]models.Person, len(misses))
+	for i, person := range misses {
+		before[i] = *person
+	}
+	err := runEnricher(ctx, e.Enricher, misses)
+	for i, person := range misses {
+		// Ошибки не кэшируем: запоминаем только тех, по кому провайдер ответил
+		// (ответ всегда оставляет запись о происхождении)
+		if len(person.Enrichments) == len(before[i].Enrichments) {
+			continue
+		}
+		entry := newCacheEntry(e.Name(), before[i], *person)
+		entry.Hint = hintFor(e.Enricher, &before[i])
+		e.cache.Set(ctx, entry)
+	}
+	return err
+}
+
+// newCacheEntry собирает запись кэша из принятых ответов провайдера. Сравнивать after
+// с before нельзя: при переобогащении значение может уже совпадать с ответом провайдера.
+func newCacheEntry(provider string, before, after models.Person) CacheEntry {
+	entry := CacheEntry{Provider: provider, Name: after.Name}
+	if len(after.Enrichments) > len(before.Enrichments) {
+		entry.Records = slices.Clone(after.Enrichments[len(before.Enrichments):])
+	}
+	for _, rec := range entry.Records {
+		if !rec.Accepted {
+			continue
+		}
+		switch rec.Field {
+		case "gender":
+			entry.Gender = after.Gender
+		case "nationality":
+			entry.Nationality = after.Nationality
+		case "age":
+			if after.Age != nil {
+				age := *after.Age
+				entry.Age = &age
+			}
+		}
+	}
+	return entry
+}
+
+// applyCacheEntry переносит непустые поля записи кэша в person
+func applyCacheEntry(person *models.Person, entry CacheEntry) {
+	if entry.Gender != "" {
+		person.Gender = entry.Gender
+	}
+	if entry.Nationality != "" {
+		person.Nationality = entry.Nationality
+	}
+	if entry.Age != nil {
+		age := *entry.Age
+		person.Age = &age
+	}
+	// Время запроса остаётся исходным, чтобы было видно возраст данных
+	for _, rec := range entry.Records {
+		rec.ID, rec.PersonID, rec.Cached = 0, 0, true
+		person.Enrichments = append(person.Enrichments, rec)
+	}
+}
@@ -0,0 +1,95 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"person-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cacheRow строка таблицы enrichment_cache
+type cacheRow struct {
+	Provider    string `gorm:"primaryKey"`
+	Name        string `gorm:"primaryKey"`
+	Hint        string `gorm:"primaryKey"`
+	Gender      string `gorm:"not null"`
+	Nationality string `gorm:"not null"`
+	Age         *int
+	StoredAt    time.Time `gorm:"not null"`
+	Records     string    `gorm:"not null"` // Записи о происхождении в JSON
+}
+
+// TableName задаёт имя таблицы кэша
+func (cacheRow) TableName() string {
+	return "enrichment_cache"
+}
+
+// DBCacheStore хранит кэш обогащения в базе, чтобы он переживал перезапуски
+type DBCacheStore struct {
+	db *gorm.DB
+}
+
+// NewDBCacheStore создаёт хранилище кэша в таблице enrichment_cache
+func NewDBCacheStore(db *gorm.DB) *DBCacheStore {
+	return &DBCacheStore{db: db}
+}
+
+// Load читает запись кэша
+func (s *DBCacheStore) Load(ctx context.Context, provider, name, hint string) (CacheEntry, bool, error) {
+	var row cacheRow
+	err := s.db.WithContext(ctx).Where("provider = ? AND name = ? AND hint = ?", provider, name, hint).Limit(1).Find(&row).Error
+	if err != nil || row.Provider == "" {
+		return CacheEntry{}, false, err
+	}
+	entry := CacheEntry{
+		Provider:    row.Provider,
+		Name:        row.Name,
+		Hint:        row.Hint,
+		Gender:      row.Gender,
+		Nationality: row.Nationality,
+		Age:         row.Age,
+		StoredAt:    row.StoredAt,
+	}
+	if row.Records != "" {
+		var records []models.PersonEnrichment
+		if err := json.Unmarshal([]byte(row.Records), &records); err != nil {
+			return CacheEntry{}, false, err
+		}
+		entry.Records = records
+	}
+	return entry, true, nil
+}
+
+// Save добавляет или обновляет запись кэша
+func (s *DBCacheStore) Save(ctx context.Context, entry CacheEntry) error {
+	records, err := json.Marshal(entry.Records)
+	if err != nil {
+		return err
+	}
+	row := cacheRow{
+		Provider:    entry.Provider,
+		Name:        entry.Name,
+		Hint:        entry.Hint,
+		Gender:      entry.Gender,
+		Nationality: entry.Nationality,
+		Age:         entry.Age,
+		StoredAt:    entry.StoredAt,
+		Records:     string(records),
+	}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+}
+
+// Delete удаляет записи по имени или все записи, если name пустое
+func (s *DBCacheStore) Delete(ctx context.Context, name string) (int64, error) {
+	query := s.db.WithContext(ctx)
+	if name == "" {
+		query = query.Where("1 = 1")
+	} else {
+		query = query.Where("name = ?", name)
+	}
+	res := query.Delete(&cacheRow{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,107 @@
+package enrichment
+
+import (
+	"context"
+	"person-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// countingEnricher считает обращения к провайдеру
+type countingEnricher struct {
+	calls int
+}
+
+func (e *countingEnricher) Name() string { return "counting" }
+
+func (e *countingEnricher) Enrich(ctx context.Context, person *models.Person) error {
+	e.calls++
+	person.Gender = "male"
+	record(person, e.Name(), "gender", "male", nil, nil, true, nil)
+	return nil
+}
+
+// TestCachedEnricher проверяет, что повторное имя не уходит к провайдеру
+func TestCachedEnricher(t *testing.T) {
+	inner := &countingEnricher{}
+	cache := NewNameCache(10, time.Hour, nil)
+	e := cache.Wrap(inner)
+
+	first := models.Person{Name: "Дмитрий"}
+	require.NoError(t, e.Enrich(context.Background(), &first))
+	second := models.Person{Name: " ДМИТРИЙ "}
+	require.NoError(t, e.Enrich(context.Background(), &second))
+
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "male", second.Gender)
+	// Происхождение из кэша помечается, время запроса сохраняется исходным
+	if assert.Len(t, second.Enrichments, 1) {
+		assert.True(t, second.Enrichments[0].Cached)
+		assert.Equal(t, first.Enrichments[0].LookedUpAt, second.Enrichments[0].LookedUpAt)
+	}
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, cache.Stats())
+}
+
+// TestCachedEnricherRefreshUnchanged проверяет, что переобогащение без изменений
+// не записывает в кэш пустой ответ для следующих людей с тем же именем
+func TestCachedEnricherRefreshUnchanged(t *testing.T) {
+	inner := &countingEnricher{}
+	cache := NewNameCache(10, time.Hour, nil)
+	e := cache.Wrap(inner)
+
+	person := models.Person{Name: "Дмитрий", Gender: "male"}
+	require.NoError(t, e.Enrich(WithRefresh(context.Background()), &person))
+	next := models.Person{Name: "Дмитрий"}
+	require.NoError(t, e.Enrich(context.Background(), &next))
+
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "male", next.Gender)
+}
+
+// TestNameCacheEvictionAndTTL проверяет вытеснение LRU и истечение TTL
+func TestNameCacheEvictionAndTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := NewNameCache(2, time.Hour, nil)
+	cache.Set(ctx, CacheEntry{Provider: "p", Name: "анна"})
+	cache.Set(ctx, CacheEntry{Provider: "p", Name: "иван"})
+	cache.Get(ctx, "p", "анна", "") // анна становится самой свежей
+	cache.Set(ctx, CacheEntry{Provider: "p", Name: "олег"})
+	_, ok := cache.Get(ctx, "p", "иван", "")
+	assert.False(t, ok)
+	_, ok = cache.Get(ctx, "p", "анна", "")
+	assert.True(t, ok)
+
+	cache.Set(ctx, CacheEntry{Provider: "p", Name: "пётр", StoredAt: time.Now().Add(-2 * time.Hour)})
+	_, ok = cache.Get(ctx, "p", "пётр", "")
+	assert.False(t, ok)
+}
+
+// TestDBCacheStore проверяет, что кэш переживает перезапуск и очищается по имени
+func TestDBCacheStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&cacheRow{}))
+	store := NewDBCacheStore(db)
+
+	age := 42
+	NewNameCache(10, time.Hour, store).Set(ctx, CacheEntry{Provider: "agify", Name: "Дмитрий", Age: &age})
+	NewNameCache(10, time.Hour, store).Set(ctx, CacheEntry{Provider: "genderize", Name: "Дмитрий", Gender: "male"})
+
+	// Новый экземпляр кэша с пустой памятью читает из хранилища
+	restarted := NewNameCache(10, time.Hour, store)
+	entry, ok := restarted.Get(ctx, "agify", "дмитрий", "")
+	require.True(t, ok)
+	assert.Equal(t, 42, *entry.Age)
+
+	purged, err := restarted.Purge(ctx, "Дмитрий")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	_, ok = restarted.Get(ctx, "genderize", "дмитрий", "")
+	assert.False(t, ok)
+}
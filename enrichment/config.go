@@ -0,0 +1,149 @@
+package enrichment
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProviderConfig настройки HTTP-провайдера обогащения
+type ProviderConfig struct {
+	BaseURL   string        // Адрес API
+	APIKey    string        // Ключ API (опционально)
+	Timeout   time.Duration // Таймаут одного запроса
+	Threshold float64       // Порог принятия ответа (вероятность или размер выборки)
+
+	MaxRetries       int           // Повторов при 5xx/429/сетевых ошибках
+	RetryBaseDelay   time.Duration // Начальная задержка повтора (удваивается)
+	RetryMaxDelay    time.Duration // Максимальная задержка повтора (0 — без ограничения)
+	BreakerThreshold int           // Ошибок подряд до размыкания circuit breaker (0 — выключен)
+	BreakerCooldown  time.Duration // Время до пробного запроса после размыкания
+}
+
+// withResilience добавляет к настройкам провайдера повторы и circuit breaker по умолчанию
+func withResilience(cfg ProviderConfig) ProviderConfig {
+	cfg.MaxRetries = 2
+	cfg.RetryBaseDelay = 100 * time.Millisecond
+	cfg.RetryMaxDelay = 2 * time.Second
+	cfg.BreakerThreshold = 5
+	cfg.BreakerCooldown = 30 * time.Second
+	return cfg
+}
+
+// DefaultGenderizeConfig настройки Genderize.io по умолчанию
+var DefaultGenderizeConfig = withResilience(ProviderConfig{BaseURL: GenderizeURL, Timeout: 2 * time.Second, Threshold: 0.7})
+
+// DefaultNationalizeConfig настройки Nationalize.io по умолчанию
+var DefaultNationalizeConfig = withResilience(ProviderConfig{BaseURL: NationalizeURL, Timeout: 2 * time.Second, Threshold: 0.3})
+
+// DefaultAgifyConfig настройки Agify.io по умолчанию
+var DefaultAgifyConfig = withResilience(ProviderConfig{BaseURL: AgifyURL, Timeout: 2 * time.Second, Threshold: 10})
+
+// DefaultBudget общий лимит времени на обогащение по умолчанию
+const DefaultBudget = 800 * time.Millisecond
+
+// LoadBudget читает общий лимит времени на обогащение из ENRICHMENT_BUDGET
+func LoadBudget() time.Duration {
+	return durationFromEnv("ENRICHMENT_BUDGET", DefaultBudget)
+}
+
+// CacheConfig настройки кэша обогащения
+type CacheConfig struct {
+	Size       int           // Максимум записей в памяти (0 — кэш в памяти выключен)
+	TTL        time.Duration // Время жизни записи
+	Persistent bool          // Хранить кэш в таблице enrichment_cache
+}
+
+// DefaultCacheConfig настройки кэша по умолчанию
+var DefaultCacheConfig = CacheConfig{Size: 10000, TTL: 30 * 24 * time.Hour}
+
+// LoadCacheConfig читает настройки кэша из ENRICHMENT_CACHE_SIZE,
+// ENRICHMENT_CACHE_TTL и ENRICHMENT_CACHE_PERSISTENT
+func LoadCacheConfig() CacheConfig {
+	cfg := DefaultCacheConfig
+	cfg.Size = intFromEnv("ENRICHMENT_CACHE_SIZE", cfg.Size)
+	cfg.TTL = durationFromEnv("ENRICHMENT_CACHE_TTL", cfg.TTL)
+	cfg.Persistent = boolFromEnv("ENRICHMENT_CACHE_PERSISTENT", cfg.Persistent)
+	return cfg
+}
+
+// LoadProviderConfig читает настройки провайдера из переменных окружения
+// <PREFIX>_URL, <PREFIX>_API_KEY, <PREFIX>_TIMEOUT, <PREFIX>_THRESHOLD,
+// <PREFIX>_MAX_RETRIES, <PREFIX>_RETRY_BASE_DELAY, <PREFIX>_RETRY_MAX_DELAY,
+// <PREFIX>_BREAKER_THRESHOLD и <PREFIX>_BREAKER_COOLDOWN.
+// Незаданные или некорректные значения берутся из defaults.
+func LoadProviderConfig(prefix string, defaults ProviderConfig) ProviderConfig {
+	cfg := defaults
+	if v := os.Getenv(prefix + "_URL"); v != "" {
+		cfg.BaseURL = v
+	}
+	if v := os.Getenv(prefix + "_API_KEY"); v != "" {
+		cfg.APIKey = v
+	}
+	cfg.Timeout = durationFromEnv(prefix+"_TIMEOUT", cfg.Timeout)
+	cfg.MaxRetries = intFromEnv(prefix+"_MAX_RETRIES", cfg.MaxRetries)
+	cfg.RetryBaseDelay = durationFromEnv(prefix+"_RETRY_BASE_DELAY", cfg.RetryBaseDelay)
+	cfg.RetryMaxDelay = durationFromEnv(prefix+"_RETRY_MAX_DELAY", cfg.RetryMaxDelay)
+	cfg.BreakerThreshold = intFromEnv(prefix+"_BREAKER_THRESHOLD", cfg.BreakerThreshold)
+	cfg.BreakerCooldown = durationFromEnv(prefix+"_BREAKER_COOLDOWN", cfg.BreakerCooldown)
+	cfg.Threshold = floatFromEnv(prefix+"_THRESHOLD", cfg.Threshold)
+	return cfg
+}
+
+// durationFromEnv читает длительность из переменной окружения или возвращает def
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		logrus.Warnf("Некорректное значение %s=%q: %v", key, v, err)
+		return def
+	}
+	return d
+}
+
+// intFromEnv читает целое число из переменной окружения или возвращает def
+func intFromEnv(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		logrus.Warnf("Некорректное значение %s=%q: %v", key, v, err)
+		return def
+	}
+	return n
+}
+
+// floatFromEnv читает число из переменной окружения или возвращает def
+func floatFromEnv(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		logrus.Warnf("Некорректное значение %s=%q: %v", key, v, err)
+		return def
+	}
+	return f
+}
+
+// boolFromEnv читает логическое значение из переменной окружения или возвращает def
+func boolFromEnv(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logrus.Warnf("Некорректное значение %s=%q: %v", key, v, err)
+		return def
+	}
+	return b
+}
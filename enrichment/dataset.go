@@ -0,0 +1,389 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"person-api/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DatasetName имя офлайн-обогатителя по справочнику имён
+const DatasetName = "dataset"
+
+// EmbeddedDataset значение ENRICHMENT_DATASET для встроенного справочника
+const EmbeddedDataset = "embedded"
+
+//go:embed data/names.csv
+var embeddedNames []byte
+
+// CountryShare доля носителей имени в стране
+type CountryShare struct {
+	CountryID   string  `json:"country_id" example:"RU"`   // Код страны
+	Probability float64 `json:"probability" example:"0.8"` // Доля
+}
+
+// DatasetEntry статистика по одному имени
+type DatasetEntry struct {
+	Name              string         `json:"name"`                         // Имя
+	Gender            string         `json:"gender,omitempty"`             // Преобладающий пол
+	GenderProbability float64        `json:"gender_probability,omitempty"` // Доля преобладающего пола
+	Countries         []CountryShare `json:"countries,omitempty"`          // Распределение по странам (по убыванию)
+	MeanAge           float64        `json:"mean_age,omitempty"`           // Средний возраст
+	Count             int            `json:"count,omitempty"`              // Размер выборки
+}
+
+// Dataset справочник имён для офлайн-обогащения
+type Dataset struct {
+	entries map[string]DatasetEntry
+}
+
+// datasetKey приводит имя к ключу справочника: как в кэше, но ещё и ё → е
+func datasetKey(name string) string {
+	return strings.ReplaceAll(NormalizeName(name), "ё", "е")
+}
+
+// NewDataset собирает справочник из записей; повторяющиеся имена заменяют предыдущие
+func NewDataset(entries []DatasetEntry) (*Dataset, error) {
+	ds := &Dataset{entries: make(map[string]DatasetEntry, len(entries))}
+	for i, e := range entries {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("запись %d (%q): %w", i+1, e.Name, err)
+		}
+		sort.SliceStable(e.Countries, func(a, b int) bool {
+			return e.Countries[a].Probability > e.Countries[b].Probability
+		})
+		e.Name = NormalizeName(e.Name)
+		ds.entries[datasetKey(e.Name)] = e
+	}
+	return ds, nil
+}
+
+// validate проверяет запись справочника
+func (e DatasetEntry) validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return errors.New("пустое имя")
+	}
+	if e.Gender != "" && e.Gender != "male" && e.Gender != "female" {
+		return fmt.Errorf("неизвестный пол %q", e.Gender)
+	}
+	if e.GenderProbability < 0 || e.GenderProbability > 1 {
+		return fmt.Errorf("вероятность пола вне [0, 1]: %v", e.GenderProbability)
+	}
+	for _, c := range e.Countries {
+		if countryCode(c.CountryID) == "" || c.Probability < 0 || c.Probability > 1 {
+			return fmt.Errorf("некорректная страна %s:%v", c.CountryID, c.Probability)
+		}
+	}
+	if e.MeanAge < 0 || e.Count < 0 {
+		return errors.New("отрицательный возраст или размер выборки")
+	}
+	return nil
+}
+
+// Lookup возвращает статистику по имени
+func (d *Dataset) Lookup(name string) (DatasetEntry, bool) {
+	e, ok := d.entries[datasetKey(name)]
+	return e, ok
+}
+
+// Len возвращает количество имён в справочнике
+func (d *Dataset) Len() int {
+	return len(d.entries)
+}
+
+// Entries возвращает записи справочника, отсортированные по имени
+func (d *Dataset) Entries() []DatasetEntry {
+	entries := make([]DatasetEntry, 0, len(d.entries))
+	for _, e := range d.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// ParseDataset разбирает справочник в формате CSV или JSON (format: "csv" или "json").
+// CSV: заголовок name,gender,gender_probability,countries,mean_age,count;
+// страны перечисляются как RU:0.8;UA:0.1. JSON: массив DatasetEntry.
+func ParseDataset(r io.Reader, format string) (*Dataset, error) {
+	switch format {
+	case "json":
+		var entries []DatasetEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("разбор JSON: %w", err)
+		}
+		return NewDataset(entries)
+	case "csv":
+		entries, err := parseDatasetCSV(r)
+		if err != nil {
+			return nil, err
+		}
+		return NewDataset(entries)
+	default:
+		return nil, fmt.Errorf("неизвестный формат справочника %q", format)
+	}
+}
+
+// DatasetFormat определяет формат справочника по расширению файла
+func DatasetFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "csv"
+}
+
+// LoadDataset загружает встроенный справочник (source = "embedded") или файл по пути
+func LoadDataset(source string) (*Dataset, error) {
+	if source == EmbeddedDataset {
+		return ParseDataset(bytes.NewReader(embeddedNames), "csv")
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseDataset(f, DatasetFormat(source))
+}
+
+// datasetColumns колонки CSV-справочника
+var datasetColumns = []string{"name", "gender", "gender_probability", "countries", "mean_age", "count"}
+
+func parseDatasetCSV(r io.Reader) ([]DatasetEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("чтение заголовка CSV: %w", err)
+	}
+	index := map[string]int{}
+	for i, col := range header {
+		index[strings.TrimSpace(strings.ToLower(col))] = i
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, errors.New("в CSV нет колонки name")
+	}
+	var entries []DatasetEntry
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		entry := DatasetEntry{Name: field("name"), Gender: field("gender")}
+		if entry.GenderProbability, err = parseFloat(field("gender_probability")); err != nil {
+			return nil, fmt.Errorf("строка %d: gender_probability: %w", line, err)
+		}
+		if entry.MeanAge, err = parseFloat(field("mean_age")); err != nil {
+			return nil, fmt.Errorf("строка %d: mean_age: %w", line, err)
+		}
+		if v := field("count"); v != "" {
+			if entry.Count, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("строка %d: count: %w", line, err)
+			}
+		}
+		if entry.Countries, err = parseCountries(field("countries")); err != nil {
+			return nil, fmt.Errorf("строка %d: countries: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func parseFloat(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// parseCountries разбирает распределение по странам вида RU:0.8;UA:0.1
+func parseCountries(v string) ([]CountryShare, error) {
+	var countries []CountryShare
+	for _, part := range strings.Split(v, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, prob, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("ожидается КОД:доля, получено %q", part)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(prob), 64)
+		if err != nil {
+			return nil, err
+		}
+		countries = append(countries, CountryShare{CountryID: strings.ToUpper(strings.TrimSpace(id)), Probability: p})
+	}
+	return countries, nil
+}
+
+// WriteDataset записывает справочник в формате CSV или JSON
+func WriteDataset(w io.Writer, ds *Dataset, format string) error {
+	entries := ds.Entries()
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(datasetColumns); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		countries := make([]string, len(e.Countries))
+		for i, c := range e.Countries {
+			countries[i] = c.CountryID + ":" + strconv.FormatFloat(c.Probability, 'f', -1, 64)
+		}
+		row := []string{
+			e.Name,
+			e.Gender,
+			strconv.FormatFloat(e.GenderProbability, 'f', -1, 64),
+			strings.Join(countries, ";"),
+			strconv.FormatFloat(e.MeanAge, 'f', -1, 64),
+			strconv.Itoa(e.Count),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// DatasetConfig настройки офлайн-справочника имён
+type DatasetConfig struct {
+	Source               string  // "embedded", путь к CSV/JSON или пусто (выключен)
+	Offline              bool    // Не обращаться к внешним API совсем
+	GenderThreshold      float64 // Минимальная доля пола
+	NationalityThreshold float64 // Минимальная доля самой частой страны
+	AgeThreshold         float64 // Минимальный размер выборки для среднего возраста
+}
+
+// LoadDatasetConfig читает настройки справочника из ENRICHMENT_DATASET, ENRICHMENT_OFFLINE,
+// ENRICHMENT_DATASET_GENDER_THRESHOLD, ENRICHMENT_DATASET_NATIONALITY_THRESHOLD
+// и ENRICHMENT_DATASET_AGE_THRESHOLD
+func LoadDatasetConfig() DatasetConfig {
+	return DatasetConfig{
+		Source:               os.Getenv("ENRICHMENT_DATASET"),
+		Offline:              boolFromEnv("ENRICHMENT_OFFLINE", false),
+		GenderThreshold:      floatFromEnv("ENRICHMENT_DATASET_GENDER_THRESHOLD", DefaultGenderizeConfig.Threshold),
+		NationalityThreshold: floatFromEnv("ENRICHMENT_DATASET_NATIONALITY_THRESHOLD", DefaultNationalizeConfig.Threshold),
+		AgeThreshold:         floatFromEnv("ENRICHMENT_DATASET_AGE_THRESHOLD", DefaultAgifyConfig.Threshold),
+	}
+}
+
+// DatasetStatus состояние загруженного справочника
+type DatasetStatus struct {
+	Source   string    `json:"source" example:"embedded"` // Источник
+	Names    int       `json:"names" example:"40"`        // Количество имён
+	LoadedAt time.Time `json:"loaded_at"`                 // Время загрузки
+}
+
+// loadedDataset справочник вместе с временем загрузки
+type loadedDataset struct {
+	data     *Dataset
+	loadedAt time.Time
+}
+
+// DatasetEnricher определяет пол, национальность и возраст по справочнику имён без сети.
+// Может заменять Genderize, Nationalize и Agify в изолированных окружениях.
+type DatasetEnricher struct {
+	cfg     DatasetConfig
+	current atomic.Pointer[loadedDataset]
+}
+
+// NewDatasetEnricher загружает справочник из cfg.Source
+func NewDatasetEnricher(cfg DatasetConfig) (*DatasetEnricher, error) {
+	d := &DatasetEnricher{cfg: cfg}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload перечитывает справочник из источника; при ошибке остаётся прежний
+func (d *DatasetEnricher) Reload() error {
+	ds, err := LoadDataset(d.cfg.Source)
+	if err != nil {
+		return fmt.Errorf("загрузка справочника %s: %w", d.cfg.Source, err)
+	}
+	d.current.Store(&loadedDataset{data: ds, loadedAt: time.Now()})
+	return nil
+}
+
+// Status возвращает состояние загруженного справочника
+func (d *DatasetEnricher) Status() DatasetStatus {
+	cur := d.current.Load()
+	return DatasetStatus{Source: d.cfg.Source, Names: cur.data.Len(), LoadedAt: cur.loadedAt}
+}
+
+// Name возвращает имя обогатителя
+func (d *DatasetEnricher) Name() string {
+	return DatasetName
+}
+
+// Offline сообщает, что справочник работает без сети и запускается до удалённых провайдеров
+func (d *DatasetEnricher) Offline() bool {
+	return true
+}
+
+// Fields возвращает заполняемые поля
+func (d *DatasetEnricher) Fields() []string {
+	return []string{"gender", "nationality", "age"}
+}
+
+// Enrich заполняет поля по справочнику; имени нет в справочнике — ничего не записывается
+func (d *DatasetEnricher) Enrich(ctx context.Context, person *models.Person) error {
+	entry, ok := d.current.Load().data.Lookup(person.Name)
+	if !ok {
+		return nil
+	}
+	var count *int
+	if entry.Count > 0 {
+		count = &entry.Count
+	}
+	if entry.Gender != "" {
+		accepted := entry.GenderProbability > d.cfg.GenderThreshold
+		if accepted {
+			person.Gender = entry.Gender
+		}
+		record(person, DatasetName, "gender", entry.Gender, &entry.GenderProbability, count, accepted, entry)
+	}
+	if len(entry.Countries) > 0 {
+		top := entry.Countries[0]
+		accepted := top.Probability > d.cfg.NationalityThreshold
+		if accepted {
+			person.Nationality = top.CountryID
+		}
+		record(person, DatasetName, "nationality", top.CountryID, &top.Probability, count, accepted, entry)
+	}
+	if entry.MeanAge > 0 {
+		// Как у Agify: средний возраст по маленькой выборке не принимается
+		age := int(math.Round(entry.MeanAge))
+		accepted := float64(entry.Count) >= d.cfg.AgeThreshold
+		if accepted {
+			person.Age = &age
+		}
+		record(person, DatasetName, "age", strconv.Itoa(age), nil, count, accepted, entry)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"person-api/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDatasetEmbedded проверяет обогащение по встроенному справочнику
+func TestDatasetEmbedded(t *testing.T) {
+	d, err := NewDatasetEnricher(DatasetConfig{Source: EmbeddedDataset, GenderThreshold: 0.7, NationalityThreshold: 0.3, AgeThreshold: 10})
+	assert.NoError(t, err)
+	assert.Greater(t, d.Status().Names, 10)
+
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, d.Enrich(context.Background(), &person))
+	assert.Equal(t, "male", person.Gender)
+	assert.Equal(t, "RU", person.Nationality)
+	assert.NotNil(t, person.Age)
+	assert.Len(t, person.Enrichments, 3)
+
+	// ё и е не различаются
+	person = models.Person{Name: "Артем"}
+	assert.NoError(t, d.Enrich(context.Background(), &person))
+	assert.Equal(t, "male", person.Gender)
+
+	person = models.Person{Name: "Zbigniew"}
+	assert.NoError(t, d.Enrich(context.Background(), &person))
+	assert.Empty(t, person.Gender)
+	assert.Empty(t, person.Enrichments)
+}
+
+// TestDatasetFormats проверяет разбор и запись CSV и JSON
+func TestDatasetFormats(t *testing.T) {
+	csvData := "name,gender,gender_probability,countries,mean_age,count\n" +
+		"Саша,male,0.6,RU:0.7;UA:0.9,30,100\n"
+	ds, err := ParseDataset(strings.NewReader(csvData), "csv")
+	assert.NoError(t, err)
+	entry, ok := ds.Lookup("САША")
+	if assert.True(t, ok) {
+		// Страны упорядочиваются по убыванию доли
+		assert.Equal(t, "UA", entry.Countries[0].CountryID)
+		assert.Equal(t, 30.0, entry.MeanAge)
+	}
+	for _, format := range []string{"csv", "json"} {
+		var buf bytes.Buffer
+		assert.NoError(t, WriteDataset(&buf, ds, format))
+		again, err := ParseDataset(&buf, format)
+		assert.NoError(t, err, format)
+		assert.Equal(t, ds.Entries(), again.Entries(), format)
+	}
+
+	_, err = ParseDataset(strings.NewReader(`[{"name":"Саша","gender":"unknown"}]`), "json")
+	assert.ErrorContains(t, err, "неизвестный пол")
+	_, err = ParseDataset(strings.NewReader("name,countries\nСаша,RU\n"), "csv")
+	assert.Error(t, err)
+}
+
+// TestDatasetAgeThreshold проверяет, что возраст по маленькой выборке не принимается
+func TestDatasetAgeThreshold(t *testing.T) {
+	ds, err := NewDataset([]DatasetEntry{{Name: "Саша", MeanAge: 30, Count: 1}, {Name: "Женя", MeanAge: 25, Count: 500}})
+	assert.NoError(t, err)
+	d := &DatasetEnricher{cfg: DatasetConfig{AgeThreshold: 10}}
+	d.current.Store(&loadedDataset{data: ds})
+
+	person := models.Person{Name: "Саша"}
+	assert.NoError(t, d.Enrich(context.Background(), &person))
+	assert.Nil(t, person.Age)
+	if assert.Len(t, person.Enrichments, 1) {
+		assert.Equal(t, "30", person.Enrichments[0].Value)
+		assert.False(t, person.Enrichments[0].Accepted)
+	}
+
+	person = models.Person{Name: "Женя"}
+	assert.NoError(t, d.Enrich(context.Background(), &person))
+	if assert.NotNil(t, person.Age) {
+		assert.Equal(t, 25, *person.Age)
+	}
+}
+
+// TestDatasetReplacesRemote проверяет, что справочник заменяет внешние API и перечитывается
+func TestDatasetReplacesRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос к провайдеру: %s", r.URL)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "names.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"name":"Саша","gender":"female","gender_probability":0.9}]`), 0o644))
+	d, err := NewDatasetEnricher(DatasetConfig{Source: path, GenderThreshold: 0.7})
+	assert.NoError(t, err)
+	r := NewRegistry(NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7}), d)
+	person := models.Person{Name: "Саша"}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "female", person.Gender)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"name":"Саша","gender":"male","gender_probability":0.9}]`), 0o644))
+	assert.NoError(t, d.Reload())
+	person = models.Person{Name: "Саша"}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "male", person.Gender)
+
+	// Испорченный файл не заменяет загруженный справочник
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	assert.Error(t, d.Reload())
+	assert.Equal(t, 1, d.Status().Names)
+}
+
+// TestRulesPrecedeDataset проверяет, что значение правил не перезаписывается справочником того же этапа
+func TestRulesPrecedeDataset(t *testing.T) {
+	ds, err := NewDataset([]DatasetEntry{{Name: "Саша", Gender: "male", GenderProbability: 0.9}})
+	assert.NoError(t, err)
+	d := &DatasetEnricher{cfg: DatasetConfig{GenderThreshold: 0.7}}
+	d.current.Store(&loadedDataset{data: ds})
+	r := NewRegistry(NewRules(), d)
+	person := models.Person{Name: "Саша", Surname: "Иванова"}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "female", person.Gender)
+	if assert.Len(t, person.Enrichments, 2) {
+		assert.True(t, person.Enrichments[0].Accepted)
+		assert.Equal(t, DatasetName, person.Enrichments[1].Provider)
+		assert.False(t, person.Enrichments[1].Accepted)
+	}
+}
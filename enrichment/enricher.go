@@ -0,0 +1,375 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"person-api/models"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Enricher дополняет данные человека сведениями из внешнего источника
+type Enricher interface {
+	// Name возвращает имя провайдера (используется в логах)
+	Name() string
+	// Enrich заполняет поля человека; ошибка не должна мешать сохранению записи
+	Enrich(ctx context.Context, person *models.Person) error
+}
+
+// Dependent обогатитель, которому нужны результаты других провайдеров.
+// Он запускается только после того, как они отработали, и видит их данные в person.
+type Dependent interface {
+	// DependsOn возвращает имена провайдеров, которые должны отработать раньше
+	DependsOn() []string
+}
+
+// Hinter обогатитель, уточняющий запрос уже известными данными человека
+// (например, страной). Подсказка входит в ключ кэша и сохраняется в происхождении.
+type Hinter interface {
+	// Hint возвращает подсказку для запроса по person или пустую строку
+	Hint(person *models.Person) string
+}
+
+// Fielder обогатитель, сообщающий, какие поля человека он заполняет (gender, nationality, age).
+// Если все его поля уже определены в текущем проходе более надёжным источником,
+// обогатитель для этого человека не вызывается.
+type Fielder interface {
+	Fields() []string
+}
+
+// Offline обогатитель, работающий без сети (правила, локальные справочники).
+// Такие обогатители запускаются первым этапом, до всех удалённых провайдеров.
+type Offline interface {
+	Offline() bool
+}
+
+// isOffline сообщает, работает ли обогатитель (под обёртками) без сети
+func isOffline(e Enricher) bool {
+	o, ok := unwrap(e).(Offline)
+	return ok && o.Offline()
+}
+
+// hintFor возвращает подсказку провайдера (под обёртками) для person
+func hintFor(e Enricher, person *models.Person) string {
+	if h, ok := unwrap(e).(Hinter); ok {
+		return h.Hint(person)
+	}
+	return ""
+}
+
+// Registry хранит настроенные обогатители и запускает их параллельно
+type Registry struct {
+	enrichers []Enricher
+	budget    time.Duration
+}
+
+// NewRegistry создаёт реестр с указанными обогатителями
+func NewRegistry(enrichers ...Enricher) *Registry {
+	return &Registry{enrichers: enrichers}
+}
+
+// Register добавляет обогатитель в конец очереди
+func (r *Registry) Register(e Enricher) {
+	r.enrichers = append(r.enrichers, e)
+}
+
+// Enrichers возвращает список зарегистрированных обогатителей
+func (r *Registry) Enrichers() []Enricher {
+	return r.enrichers
+}
+
+// Breakers возвращает состояние circuit breaker всех HTTP-провайдеров
+func (r *Registry) Breakers() []BreakerStatus {
+	var statuses []BreakerStatus
+	for _, e := range r.enrichers {
+		if b, ok := unwrap(e).(interface{ Breaker() BreakerStatus }); ok {
+			statuses = append(statuses, b.Breaker())
+		}
+	}
+	return statuses
+}
+
+// Quotas возвращает текущие квоты всех HTTP-провайдеров
+func (r *Registry) Quotas() []QuotaStatus {
+	var statuses []QuotaStatus
+	for _, e := range r.enrichers {
+		if q, ok := unwrap(e).(interface{ Quota() QuotaStatus }); ok {
+			statuses = append(statuses, q.Quota())
+		}
+	}
+	return statuses
+}
+
+// unwrap снимает обёртки (кэш и т.п.), чтобы добраться до самого провайдера
+func unwrap(e Enricher) Enricher {
+	for {
+		w, ok := e.(interface{ Unwrap() Enricher })
+		if !ok {
+			return e
+		}
+		e = w.Unwrap()
+	}
+}
+
+// WithBudget задаёт общий лимит времени на обогащение (0 — без лимита)
+func (r *Registry) WithBudget(budget time.Duration) *Registry {
+	r.budget = budget
+	return r
+}
+
+// enrichResult результат работы одного обогатителя над копиями людей
+type enrichResult struct {
+	idx    int
+	people []models.Person
+	err    error
+}
+
+// Enrich запускает обогатители параллельно (с учётом зависимостей), каждый над своей
+// копией человека, и переносит полученные поля в person в порядке регистрации.
+// По истечении бюджета не успевшие провайдеры отбрасываются, а person
+// получает только уже пришедшие данные. Ошибки провайдеров возвращаются вместе.
+func (r *Registry) Enrich(ctx context.Context, person *models.Person) error {
+	return r.EnrichBatch(ctx, []*models.Person{person})
+}
+
+// EnrichBatch обогащает сразу нескольких людей. Провайдеры, реализующие BatchEnricher,
+// получают всех людей одним вызовом и объединяют имена в пакетные запросы,
+// остальные вызываются для каждого человека по очереди.
+// Офлайн-обогатители (Offline) запускаются первыми, провайдеры с зависимостями (Dependent) —
+// следующим этапом после своих зависимостей; все этапы укладываются в общий бюджет.
+func (r *Registry) EnrichBatch(ctx context.Context, people []*models.Person) error {
+	if len(people) == 0 {
+		return nil
+	}
+	if r.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.budget)
+		defer cancel()
+	}
+	// Записи о происхождении до начала прохода: всё, что добавится после, — результат этого прохода
+	initial := make([]int, len(people))
+	for i, p := range people {
+		initial[i] = len(p.Enrichments)
+	}
+	var errs []error
+	for _, stage := range r.stages() {
+		errs = append(errs, r.runStage(ctx, stage, people, initial)...)
+	}
+	return errors.Join(errs...)
+}
+
+// stages раскладывает обогатители по этапам: каждый этап содержит индексы тех,
+// чьи зависимости уже отработали на предыдущих этапах. Зависимости от
+// незарегистрированных провайдеров игнорируются, циклы выполняются последним этапом.
+// Удалённые провайдеры неявно зависят от всех офлайн-обогатителей.
+func (r *Registry) stages() [][]int {
+	registered := make(map[string]bool, len(r.enrichers))
+	var offline []string
+	for _, e := range r.enrichers {
+		registered[e.Name()] = true
+		if isOffline(e) {
+			offline = append(offline, e.Name())
+		}
+	}
+	finished := make(map[string]bool, len(r.enrichers))
+	remaining := make([]int, len(r.enrichers))
+	for i := range r.enrichers {
+		remaining[i] = i
+	}
+	var stages [][]int
+	for len(remaining) > 0 {
+		var stage, blocked []int
+		for _, i := range remaining {
+			if dependenciesDone(r.enrichers[i], offline, registered, finished) {
+				stage = append(stage, i)
+			} else {
+				blocked = append(blocked, i)
+			}
+		}
+		if len(stage) == 0 {
+			logrus.Warnf("Циклические зависимости обогатителей: запускаем %d без ожидания", len(blocked))
+			stage, blocked = blocked, nil
+		}
+		for _, i := range stage {
+			finished[r.enrichers[i].Name()] = true
+		}
+		stages = append(stages, stage)
+		remaining = blocked
+	}
+	return stages
+}
+
+// dependenciesDone сообщает, отработали ли все зарегистрированные зависимости обогатителя
+func dependenciesDone(e Enricher, offline []string, registered, finished map[string]bool) bool {
+	var deps []string
+	if !isOffline(e) {
+		deps = offline
+	}
+	if d, ok := unwrap(e).(Dependent); ok {
+		deps = append(slices.Clip(deps), d.DependsOn()...)
+	}
+	for _, name := range deps {
+		if registered[name] && !finished[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// runStage параллельно запускает обогатители одного этапа, каждый над своими копиями людей,
+// и переносит полученные поля в people в порядке регистрации.
+// По истечении бюджета не успевшие провайдеры отбрасываются, а people
+// получают только уже пришедшие данные. Люди, у которых все поля обогатителя уже
+// определены в этом проходе, ему не передаются.
+func (r *Registry) runStage(ctx context.Context, stage []int, people []*models.Person, initial []int) []error {
+	// Буфер на всех, чтобы опоздавшие горутины не блокировались после выхода.
+	// Горутин не ждём: по истечении бюджета результаты собираются без них.
+	results := make(chan enrichResult, len(stage))
+	launched := make(map[int]bool, len(stage))
+	for _, i := range stage {
+		e := r.enrichers[i]
+		// Копии снимаем до запуска горутины: после бюджета people меняются без ожидания провайдеров
+		local := snapshot(people)
+		var ptrs []*models.Person
+		for j := range local {
+			if needed(e, &local[j], initial[j]) {
+				ptrs = append(ptrs, &local[j])
+			}
+		}
+		if len(ptrs) == 0 {
+			continue
+		}
+		launched[i] = true
+		go func() {
+			start := time.Now()
+			err := runEnricher(ctx, e, ptrs)
+			logrus.WithFields(logrus.Fields{
+				"provider":    e.Name(),
+				"people":      len(ptrs),
+				"duration_ms": time.Since(start).Milliseconds(),
+				"success":     err == nil,
+			}).Info("Запрос обогащения завершён")
+			results <- enrichResult{idx: i, people: local, err: err}
+		}()
+	}
+	done := make(map[int]*enrichResult, len(launched))
+collect:
+	for received := 0; received < len(launched); received++ {
+		select {
+		case res := <-results:
+			done[res.idx] = &res
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	var errs []error
+	before := snapshot(people)
+	for _, i := range stage {
+		name := r.enrichers[i].Name()
+		if !launched[i] {
+			continue
+		}
+		res := done[i]
+		if res == nil {
+			logrus.Warnf("Обогащение %s не уложилось в бюджет", name)
+			errs = append(errs, fmt.Errorf("%s: %w", name, ctx.Err()))
+			continue
+		}
+		if res.err != nil {
+			logrus.Warnf("Обогащение %s не удалось: %v", name, res.err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, res.err))
+		}
+		// Даже при ошибке пакетного провайдера часть людей могла быть обогащена
+		for j := range people {
+			mergeEnriched(people[j], before[j], res.people[j], initial[j])
+		}
+	}
+	return errs
+}
+
+// needed сообщает, нужен ли обогатитель человеку: нет, если все его поля
+// указаны клиентом или уже приняты из другого источника в текущем проходе (записи после start)
+func needed(e Enricher, person *models.Person, start int) bool {
+	f, ok := unwrap(e).(Fielder)
+	if !ok {
+		return true
+	}
+	for _, field := range f.Fields() {
+		if !resolved(person, field, start) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolved сообщает, задано ли поле клиентом или принято ли его значение
+// в записях о происхождении начиная с start
+func resolved(person *models.Person, field string, start int) bool {
+	if person.FieldSource(field) == models.SourceClient {
+		return true
+	}
+	for _, rec := range person.Enrichments[start:] {
+		if rec.Field == field && rec.Accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// runEnricher обогащает людей одним провайдером: пакетом, если он это умеет, иначе по одному
+func runEnricher(ctx context.Context, e Enricher, people []*models.Person) error {
+	if be, ok := e.(BatchEnricher); ok && len(people) > 1 {
+		return be.EnrichBatch(ctx, people)
+	}
+	var errs []error
+	for _, p := range people {
+		if err := e.Enrich(ctx, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshot копирует людей; ёмкость Enrichments обрезается,
+// чтобы append записей происхождения в копии не делил общий массив
+func snapshot(people []*models.Person) []models.Person {
+	copies := make([]models.Person, len(people))
+	for i, p := range people {
+		copies[i] = *p
+		copies[i].Enrichments = slices.Clip(p.Enrichments)
+	}
+	return copies
+}
+
+// mergeEnriched переносит в dst поля, изменённые обогатителем относительно before.
+// Поле, указанное клиентом или уже принятое в этом проходе (записи dst после initial),
+// не перезаписывается: побеждает обогатитель более раннего этапа, а внутри этапа —
+// зарегистрированный раньше. Перенесённые поля помечаются источником provider.
+// Записи проигравшего обогатителя сохраняются как непринятые.
+func mergeEnriched(dst *models.Person, before, after models.Person, initial int) {
+	taken := map[string]bool{}
+	for _, field := range []string{"gender", "nationality", "age"} {
+		taken[field] = resolved(dst, field, initial)
+	}
+	if after.Gender != before.Gender && !taken["gender"] {
+		dst.Gender = after.Gender
+		dst.GenderSource = models.SourceProvider
+	}
+	if after.Nationality != before.Nationality && !taken["nationality"] {
+		dst.Nationality = after.Nationality
+		dst.NationalitySource = models.SourceProvider
+	}
+	if !models.SameAge(after.Age, before.Age) && !taken["age"] {
+		dst.Age = after.Age
+		dst.AgeSource = models.SourceProvider
+	}
+	for _, rec := range after.Enrichments[len(before.Enrichments):] {
+		if taken[rec.Field] {
+			rec.Accepted = false
+		}
+		dst.Enrichments = append(dst.Enrichments, rec)
+	}
+}
@@ -0,0 +1,322 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"person-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer поднимает локальную замену внешнего API
+func newTestServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Дмитрий", r.URL.Query().Get("name"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestGenderize проверяет порог вероятности Genderize.io
+func TestGenderize(t *testing.T) {
+	srv := newTestServer(t, `{"gender":"male","probability":0.99}`)
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, g.Enrich(context.Background(), &person))
+	assert.Equal(t, "male", person.Gender)
+
+	srv = newTestServer(t, `{"gender":"female","probability":0.5}`)
+	g = NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+	person = models.Person{Name: "Дмитрий"}
+	assert.NoError(t, g.Enrich(context.Background(), &person))
+	assert.Empty(t, person.Gender)
+	// Неуверенный ответ всё равно записывается в происхождение
+	if assert.Len(t, person.Enrichments, 1) {
+		rec := person.Enrichments[0]
+		assert.Equal(t, "genderize", rec.Provider)
+		assert.Equal(t, "female", rec.Value)
+		assert.False(t, rec.Accepted)
+		assert.InDelta(t, 0.5, *rec.Probability, 1e-9)
+	}
+}
+
+// TestNationalize проверяет выбор самой вероятной страны
+func TestNationalize(t *testing.T) {
+	srv := newTestServer(t, `{"country":[{"country_id":"RU","probability":0.8},{"country_id":"UA","probability":0.1}]}`)
+	n := NewNationalize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.3})
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, n.Enrich(context.Background(), &person))
+	assert.Equal(t, "RU", person.Nationality)
+}
+
+// TestAgify проверяет порог размера выборки Agify.io
+func TestAgify(t *testing.T) {
+	srv := newTestServer(t, `{"name":"Дмитрий","age":42,"count":1500}`)
+	a := NewAgify(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 10})
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, a.Enrich(context.Background(), &person))
+	if assert.NotNil(t, person.Age) {
+		assert.Equal(t, 42, *person.Age)
+	}
+
+	srv = newTestServer(t, `{"name":"Дмитрий","age":30,"count":3}`)
+	a = NewAgify(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 10})
+	person = models.Person{Name: "Дмитрий"}
+	assert.NoError(t, a.Enrich(context.Background(), &person))
+	assert.Nil(t, person.Age)
+}
+
+// TestProviderTimeoutAndAPIKey проверяет передачу ключа API и отмену медленного запроса
+func TestProviderTimeoutAndAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("apikey"))
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, APIKey: "secret", Timeout: 20 * time.Millisecond, Threshold: 0.7})
+	start := time.Now()
+	err := g.Enrich(context.Background(), &models.Person{Name: "Дмитрий"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+// TestLoadProviderConfig проверяет чтение настроек из окружения
+func TestLoadProviderConfig(t *testing.T) {
+	t.Setenv("GENDERIZE_URL", "http://localhost:9000")
+	t.Setenv("GENDERIZE_TIMEOUT", "500ms")
+	t.Setenv("GENDERIZE_THRESHOLD", "abc")
+	t.Setenv("GENDERIZE_RETRY_BASE_DELAY", "50ms")
+	t.Setenv("GENDERIZE_RETRY_MAX_DELAY", "1s")
+	cfg := LoadProviderConfig("GENDERIZE", DefaultGenderizeConfig)
+	assert.Equal(t, "http://localhost:9000", cfg.BaseURL)
+	assert.Equal(t, 500*time.Millisecond, cfg.Timeout)
+	assert.Equal(t, DefaultGenderizeConfig.Threshold, cfg.Threshold)
+	assert.Equal(t, 50*time.Millisecond, cfg.RetryBaseDelay)
+	assert.Equal(t, time.Second, cfg.RetryMaxDelay)
+}
+
+// TestBackoff проверяет границы задержки, в том числе без максимума
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 70; attempt++ {
+		d := backoff(attempt, 10*time.Millisecond, 0)
+		assert.Positive(t, d)
+		d = backoff(attempt, 10*time.Millisecond, 100*time.Millisecond)
+		assert.Positive(t, d)
+		assert.LessOrEqual(t, d, 100*time.Millisecond)
+	}
+	assert.Zero(t, backoff(3, 0, time.Second))
+}
+
+type funcEnricher struct {
+	name string
+	fn   func(*models.Person) error
+}
+
+func (f funcEnricher) Name() string { return f.name }
+
+func (f funcEnricher) Enrich(ctx context.Context, person *models.Person) error { return f.fn(person) }
+
+// TestRegistryContinuesOnError проверяет, что ошибка одного провайдера не останавливает остальные
+func TestRegistryContinuesOnError(t *testing.T) {
+	r := NewRegistry(
+		funcEnricher{"broken", func(*models.Person) error { return errors.New("недоступен") }},
+		funcEnricher{"gender", func(p *models.Person) error { p.Gender = "male"; return nil }},
+	)
+	person := models.Person{Name: "Дмитрий"}
+	err := r.Enrich(context.Background(), &person)
+	assert.ErrorContains(t, err, "broken")
+	assert.Equal(t, "male", person.Gender)
+}
+
+// TestRegistryRunsConcurrently проверяет параллельный запуск провайдеров
+func TestRegistryRunsConcurrently(t *testing.T) {
+	slow := func(d time.Duration, fn func(*models.Person)) func(*models.Person) error {
+		return func(p *models.Person) error { time.Sleep(d); fn(p); return nil }
+	}
+	r := NewRegistry(
+		funcEnricher{"gender", slow(100*time.Millisecond, func(p *models.Person) { p.Gender = "male" })},
+		funcEnricher{"nationality", slow(100*time.Millisecond, func(p *models.Person) { p.Nationality = "RU" })},
+	)
+	person := models.Person{Name: "Дмитрий"}
+	start := time.Now()
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Less(t, time.Since(start), 180*time.Millisecond)
+	assert.Equal(t, "male", person.Gender)
+	assert.Equal(t, "RU", person.Nationality)
+}
+
+// TestSameAgeByValue проверяет, что равный возраст в другом указателе не считается изменением
+func TestSameAgeByValue(t *testing.T) {
+	age := 42
+	r := NewRegistry(funcEnricher{"age", func(p *models.Person) error { same := 42; p.Age = &same; return nil }})
+	person := models.Person{Name: "Дмитрий", Age: &age}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Empty(t, person.AgeSource)
+}
+
+// TestRegistryBudget проверяет, что по истечении бюджета сохраняются уже полученные данные
+func TestRegistryBudget(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	r := NewRegistry(
+		funcEnricher{"hanging", func(p *models.Person) error { <-release; p.Nationality = "RU"; return nil }},
+		funcEnricher{"gender", func(p *models.Person) error { p.Gender = "male"; return nil }},
+	).WithBudget(50 * time.Millisecond)
+	person := models.Person{Name: "Дмитрий"}
+	start := time.Now()
+	err := r.Enrich(context.Background(), &person)
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "hanging")
+	assert.Equal(t, "male", person.Gender)
+	assert.Empty(t, person.Nationality)
+}
+
+// TestUpstreamRetries проверяет повтор после 503 и уважение Retry-After при 429
+func TestUpstreamRetries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"gender":"male","probability":0.99}`))
+		}
+	}))
+	defer srv.Close()
+	cfg := ProviderConfig{BaseURL: srv.URL, Threshold: 0.7, MaxRetries: 2, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 2 * time.Second}
+	g := NewGenderize(srv.Client(), cfg)
+	person := models.Person{Name: "Дмитрий"}
+	start := time.Now()
+	assert.NoError(t, g.Enrich(context.Background(), &person))
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, "male", person.Gender)
+}
+
+// TestUpstreamNoRetryOnClientError проверяет, что 4xx не повторяется и не размыкает breaker
+func TestUpstreamNoRetryOnClientError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, MaxRetries: 3, RetryBaseDelay: time.Millisecond, RetryMaxDelay: time.Millisecond, BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	assert.Error(t, g.Enrich(context.Background(), &models.Person{Name: "Дмитрий"}))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, BreakerClosed, g.Breaker().State)
+}
+
+// TestCircuitBreaker проверяет размыкание после серии ошибок и пробный запрос после паузы
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"gender":"male","probability":0.99}`))
+	}))
+	defer srv.Close()
+	cfg := ProviderConfig{BaseURL: srv.URL, Threshold: 0.7, BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond}
+	g := NewGenderize(srv.Client(), cfg)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		assert.Error(t, g.Enrich(ctx, &models.Person{Name: "Дмитрий"}))
+	}
+	assert.Equal(t, BreakerOpen, g.Breaker().State)
+	// Пока breaker разомкнут, провайдер не вызывается
+	assert.ErrorIs(t, g.Enrich(ctx, &models.Person{Name: "Дмитрий"}), ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, BreakerHalfOpen, g.Breaker().State)
+	healthy = true
+	assert.NoError(t, g.Enrich(ctx, &models.Person{Name: "Дмитрий"}))
+	assert.Equal(t, BreakerClosed, g.Breaker().State)
+
+	// Реестр видит breaker сквозь обёртку кэша
+	r := NewRegistry(NewNameCache(10, time.Hour, nil).Wrap(g))
+	if assert.Len(t, r.Breakers(), 1) {
+		assert.Equal(t, "genderize", r.Breakers()[0].Provider)
+	}
+}
+
+// TestCircuitBreakerCancelledProbe проверяет, что прерванный пробный запрос не оставляет breaker полуоткрытым навсегда
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	calls := 0
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"gender":"male","probability":0.99}`))
+	}))
+	defer srv.Close()
+	cfg := ProviderConfig{BaseURL: srv.URL, Threshold: 0.7, BreakerThreshold: 1, BreakerCooldown: 50 * time.Millisecond}
+	g := NewGenderize(srv.Client(), cfg)
+	assert.Error(t, g.Enrich(context.Background(), &models.Person{Name: "Дмитрий"}))
+	assert.Equal(t, BreakerOpen, g.Breaker().State)
+
+	time.Sleep(60 * time.Millisecond)
+	// Пробный запрос прерван снаружи: провайдер не вызван, состояние не изменилось
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, g.Enrich(ctx, &models.Person{Name: "Дмитрий"}), context.Canceled)
+	assert.Equal(t, BreakerHalfOpen, g.Breaker().State)
+	assert.Equal(t, 1, calls)
+
+	// Следующий запрос снова пропускается как пробный
+	healthy = true
+	assert.NoError(t, g.Enrich(context.Background(), &models.Person{Name: "Дмитрий"}))
+	assert.Equal(t, BreakerClosed, g.Breaker().State)
+	assert.Equal(t, 2, calls)
+}
+
+// TestUpstreamQuota проверяет, что при исчерпанной квоте провайдер не вызывается до её сброса
+func TestUpstreamQuota(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Rate-Limit-Limit", "1000")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", "3600")
+		w.Write([]byte(`{"gender":"male","probability":0.99}`))
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+	ctx := context.Background()
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, g.Enrich(ctx, &person))
+	assert.Equal(t, "male", person.Gender)
+
+	quota := g.Quota()
+	assert.True(t, quota.Exhausted)
+	assert.Equal(t, 1000, quota.Limit)
+
+	r := NewRegistry(g)
+	err := r.Enrich(ctx, &models.Person{Name: "Иван"})
+	resetAt, ok := QuotaResetAt(err)
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), resetAt, time.Minute)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, BreakerClosed, g.Breaker().State)
+}
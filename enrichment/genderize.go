@@ -0,0 +1,100 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"person-api/models"
+	"strings"
+)
+
+// GenderizeURL адрес API Genderize.io по умолчанию
+const GenderizeURL = "https://api.genderize.io"
+
+// GenderizeResponse структура ответа от Genderize.io
+type GenderizeResponse struct {
+	Name        string  `json:"name"`        // Запрошенное имя
+	Gender      string  `json:"gender"`      // Пол
+	Probability float64 `json:"probability"` // Вероятность
+	Count       int     `json:"count"`       // Размер выборки
+}
+
+func (r GenderizeResponse) requestedName() string {
+	return r.Name
+}
+
+// Genderize определяет пол по имени через Genderize.io
+type Genderize struct {
+	*Upstream
+}
+
+// NewGenderize создаёт провайдер Genderize.io
+func NewGenderize(client *http.Client, cfg ProviderConfig) *Genderize {
+	return &Genderize{newUpstream("genderize", client, cfg)}
+}
+
+// Fields возвращает заполняемые поля
+func (g *Genderize) Fields() []string {
+	return []string{"gender"}
+}
+
+// DependsOn запускает Genderize после Nationalize, чтобы уточнить запрос страной
+func (g *Genderize) DependsOn() []string {
+	return []string{"nationalize"}
+}
+
+// Hint возвращает подсказку country_id по уже известной национальности человека
+func (g *Genderize) Hint(person *models.Person) string {
+	if country := countryCode(person.Nationality); country != "" {
+		return "country_id=" + country
+	}
+	return ""
+}
+
+// Enrich заполняет пол, если вероятность выше порога.
+// Известная национальность передаётся провайдеру как country_id.
+func (g *Genderize) Enrich(ctx context.Context, person *models.Person) error {
+	params := g.params(person)
+	params.Set("name", person.Name)
+	var resp GenderizeResponse
+	if err := g.getJSON(ctx, params, &resp); err != nil {
+		return err
+	}
+	g.apply(person, resp)
+	return nil
+}
+
+// EnrichBatch определяет пол для нескольких людей пачками по BatchSize имён;
+// люди с разными странами запрашиваются разными пачками
+func (g *Genderize) EnrichBatch(ctx context.Context, people []*models.Person) error {
+	return lookupBatch(ctx, g.Upstream, people, g.params, g.apply)
+}
+
+// params возвращает параметр country_id для человека с известной национальностью
+func (g *Genderize) params(person *models.Person) url.Values {
+	params := url.Values{}
+	if country := countryCode(person.Nationality); country != "" {
+		params.Set("country_id", country)
+	}
+	return params
+}
+
+// apply переносит ответ провайдера в person
+func (g *Genderize) apply(person *models.Person, resp GenderizeResponse) {
+	accepted := resp.Gender != "" && resp.Probability > g.Config.Threshold
+	if accepted {
+		person.Gender = resp.Gender
+	}
+	record(person, g.Name(), "gender", resp.Gender, &resp.Probability, &resp.Count, accepted, resp)
+	person.Enrichments[len(person.Enrichments)-1].Hint = g.Hint(person)
+}
+
+// countryCode возвращает национальность как код страны ISO 3166-1 alpha-2
+// или пустую строку, если значение на код не похоже
+func countryCode(nationality string) string {
+	code := strings.ToUpper(strings.TrimSpace(nationality))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,89 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"person-api/models"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dependentEnricher тестовый обогатитель с зависимостями
+type dependentEnricher struct {
+	funcEnricher
+	deps []string
+}
+
+func (d dependentEnricher) DependsOn() []string { return d.deps }
+
+// TestRegistryDependencies проверяет, что зависимый провайдер видит результат своей зависимости
+func TestRegistryDependencies(t *testing.T) {
+	var seen string
+	r := NewRegistry(
+		dependentEnricher{funcEnricher{"gender", func(p *models.Person) error { seen = p.Nationality; return nil }}, []string{"nationality"}},
+		funcEnricher{"nationality", func(p *models.Person) error { p.Nationality = "RU"; return nil }},
+		// Зависимость от незарегистрированного провайдера не блокирует запуск
+		dependentEnricher{funcEnricher{"age", func(p *models.Person) error { p.Age = new(int); return nil }}, []string{"missing"}},
+	)
+	assert.Equal(t, [][]int{{1, 2}, {0}}, r.stages())
+	person := models.Person{Name: "Дмитрий"}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "RU", seen)
+	assert.NotNil(t, person.Age)
+}
+
+// TestGenderizeCountryHint проверяет передачу country_id, запись подсказки и раздельный кэш по странам
+func TestGenderizeCountryHint(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		gender := "female"
+		if r.URL.Query().Get("country_id") == "RU" {
+			gender = "male"
+		}
+		if names := r.URL.Query()["name[]"]; len(names) > 0 {
+			resp := make([]GenderizeResponse, len(names))
+			for i, name := range names {
+				resp[i] = GenderizeResponse{Name: name, Gender: gender, Probability: 0.95}
+			}
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+		json.NewEncoder(w).Encode(GenderizeResponse{Name: r.URL.Query().Get("name"), Gender: gender, Probability: 0.95})
+	}))
+	defer srv.Close()
+	g := NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7})
+	cached := NewNameCache(100, 0, nil).Wrap(g)
+
+	ru := models.Person{Name: "Саша", Nationality: "ru"}
+	assert.NoError(t, cached.Enrich(context.Background(), &ru))
+	assert.Equal(t, "male", ru.Gender)
+	if assert.Len(t, ru.Enrichments, 1) {
+		assert.Equal(t, "country_id=RU", ru.Enrichments[0].Hint)
+	}
+	// То же имя без страны — отдельная запись кэша
+	plain := models.Person{Name: "Саша"}
+	assert.NoError(t, cached.Enrich(context.Background(), &plain))
+	assert.Equal(t, "female", plain.Gender)
+	assert.Empty(t, plain.Enrichments[0].Hint)
+	assert.EqualValues(t, 2, requests.Load())
+	// Повтор со страной берётся из кэша вместе с подсказкой
+	again := models.Person{Name: "саша", Nationality: "RU"}
+	assert.NoError(t, cached.Enrich(context.Background(), &again))
+	assert.Equal(t, "male", again.Gender)
+	assert.Equal(t, "country_id=RU", again.Enrichments[0].Hint)
+	assert.EqualValues(t, 2, requests.Load())
+
+	// В пакете люди с разными странами идут разными запросами
+	requests.Store(0)
+	people := []*models.Person{{Name: "Женя", Nationality: "RU"}, {Name: "Женя"}, {Name: "Валя", Nationality: "RU"}}
+	assert.NoError(t, g.EnrichBatch(context.Background(), people))
+	assert.EqualValues(t, 2, requests.Load())
+	assert.Equal(t, "male", people[0].Gender)
+	assert.Equal(t, "female", people[1].Gender)
+	assert.Equal(t, "male", people[2].Gender)
+}
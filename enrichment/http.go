@@ -0,0 +1,218 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewHTTPClient создаёт общий HTTP-клиент для всех провайдеров.
+// Таймауты задаются для каждого запроса через контекст, поэтому у клиента их нет.
+func NewHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	transport.IdleConnTimeout = 90 * time.Second
+	return &http.Client{Transport: transport}
+}
+
+// Upstream доступ к HTTP API одного провайдера: таймауты, повторы с backoff
+// и circuit breaker. Встраивается в конкретные провайдеры.
+type Upstream struct {
+	Config  ProviderConfig // Настройки провайдера
+	client  *http.Client
+	name    string
+	breaker *CircuitBreaker
+	quota   QuotaTracker
+}
+
+// newUpstream создаёт доступ к API провайдера name
+func newUpstream(name string, client *http.Client, cfg ProviderConfig) *Upstream {
+	return &Upstream{
+		Config:  cfg,
+		client:  client,
+		name:    name,
+		breaker: NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// Name возвращает имя провайдера
+func (u *Upstream) Name() string {
+	return u.name
+}
+
+// Breaker возвращает состояние circuit breaker провайдера
+func (u *Upstream) Breaker() BreakerStatus {
+	return u.breaker.Status(u.name)
+}
+
+// Quota возвращает текущую квоту провайдера
+func (u *Upstream) Quota() QuotaStatus {
+	return u.quota.Status(u.name)
+}
+
+// statusError ошибка HTTP-статуса от провайдера
+type statusError struct {
+	code       int
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("неожиданный статус ответа: %d", e.code)
+}
+
+// retryable сообщает, стоит ли повторять запрос и учитывать ошибку в circuit breaker:
+// сетевые ошибки, таймауты провайдера, 5xx и 429
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= 500
+	}
+	var de *decodeError
+	return !errors.As(err, &de)
+}
+
+// decodeError ответ провайдера не удалось разобрать
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("некорректный ответ провайдера: %v", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// getJSON выполняет GET-запрос к провайдеру и декодирует JSON-ответ в out.
+// Сетевые ошибки, 5xx и 429 повторяются с экспоненциальной задержкой и jitter
+// (с учётом Retry-After); при открытом circuit breaker запрос не выполняется.
+func (u *Upstream) getJSON(ctx context.Context, params url.Values, out interface{}) error {
+	if u.Config.APIKey != "" {
+		params.Set("apikey", u.Config.APIKey)
+	}
+	// Квоту проверяем до breaker: исчерпанная квота — не сбой провайдера
+	if resetAt, exhausted := u.quota.Exhausted(); exhausted {
+		return &QuotaExhaustedError{Provider: u.name, ResetAt: resetAt}
+	}
+	if !u.breaker.Allow() {
+		return fmt.Errorf("%s: %w", u.name, ErrCircuitOpen)
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = u.attempt(ctx, params, out)
+		if err == nil {
+			u.breaker.Success()
+			return nil
+		}
+		// Отмена снаружи (бюджет обогащения, закрытый запрос) — не вина провайдера
+		if ctx.Err() != nil {
+			u.breaker.Release()
+			return err
+		}
+		if !retryable(err) {
+			u.breaker.Success()
+			return err
+		}
+		if resetAt, exhausted := u.quota.Exhausted(); exhausted {
+			// Провайдер ответил, просто квота кончилась — повторять до сброса бесполезно
+			u.breaker.Success()
+			return &QuotaExhaustedError{Provider: u.name, ResetAt: resetAt}
+		}
+		if attempt >= u.Config.MaxRetries {
+			break
+		}
+		delay := backoff(attempt, u.Config.RetryBaseDelay, u.Config.RetryMaxDelay)
+		var se *statusError
+		if errors.As(err, &se) && se.retryAfter > 0 {
+			if u.Config.RetryMaxDelay > 0 && se.retryAfter > u.Config.RetryMaxDelay {
+				// Ждать дольше, чем разрешено, нет смысла: для 429 это фактически исчерпанная квота
+				if se.code == http.StatusTooManyRequests {
+					u.quota.ExhaustUntil(time.Now().Add(se.retryAfter))
+					u.breaker.Success()
+					return &QuotaExhaustedError{Provider: u.name, ResetAt: time.Now().Add(se.retryAfter)}
+				}
+				break
+			}
+			delay = se.retryAfter
+		}
+		logrus.Debugf("Повтор запроса к %s через %s: %v", u.name, delay, err)
+		select {
+		case <-ctx.Done():
+			u.breaker.Release()
+			return err
+		case <-time.After(delay):
+		}
+	}
+	u.breaker.Failure()
+	return err
+}
+
+// attempt выполняет одну попытку запроса с таймаутом Config.Timeout
+func (u *Upstream) attempt(ctx context.Context, params url.Values, out interface{}) error {
+	if u.Config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.Config.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.Config.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	u.quota.Update(resp.Header)
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode, retryAfter: retryAfter}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &decodeError{err: err}
+	}
+	return nil
+}
+
+// backoff возвращает задержку перед повтором: экспонента от base с полным jitter,
+// не больше max (max <= 0 — без ограничения)
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	ceiling := base << attempt
+	if ceiling>>attempt != base {
+		// Переполнение
+		ceiling = math.MaxInt64
+	}
+	if max > 0 && ceiling > max {
+		ceiling = max
+	}
+	return rand.N(ceiling) + 1
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP-дата)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package enrichment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"person-api/models"
+	"person-api/repository"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Статусы массовой задачи переобогащения
+const (
+	JobRunning = "running" // Выполняется
+	JobDone    = "done"    // Завершена
+)
+
+// JobStatus состояние массовой задачи переобогащения
+type JobStatus struct {
+	ID         string     `json:"id" example:"9f8c2a1b4d6e7f00"`           // Идентификатор задачи
+	Status     string     `json:"status" example:"running"`                // Статус задачи
+	Providers  []string   `json:"providers,omitempty" example:"genderize"` // Провайдеры (пусто — все)
+	Total      int        `json:"total" example:"100"`                     // Всего людей
+	Processed  int        `json:"processed" example:"40"`                  // Обработано
+	Failed     int        `json:"failed" example:"2"`                      // С ошибками
+	LastError  string     `json:"last_error,omitempty" example:"таймаут"`  // Последняя ошибка
+	StartedAt  time.Time  `json:"started_at"`                              // Время запуска
+	FinishedAt *time.Time `json:"finished_at,omitempty"`                   // Время завершения
+}
+
+// JobRetention сколько хранится статус завершённой задачи
+const JobRetention = time.Hour
+
+// Jobs запускает массовое переобогащение в фоне и хранит прогресс задач в памяти.
+// Завершённые задачи забываются через retention.
+type Jobs struct {
+	mu        sync.Mutex
+	jobs      map[string]*JobStatus
+	retention time.Duration
+}
+
+// NewJobs создаёт менеджер массовых задач
+func NewJobs() *Jobs {
+	return &Jobs{jobs: make(map[string]*JobStatus), retention: JobRetention}
+}
+
+// Start запускает переобогащение людей с указанными ID и сразу возвращает статус задачи
+func (j *Jobs) Start(people repository.PersonRepository, enrichers *Registry, providers []string, ids []uint) JobStatus {
+	job := &JobStatus{
+		ID:        newJobID(),
+		Status:    JobRunning,
+		Providers: providers,
+		Total:     len(ids),
+		StartedAt: time.Now(),
+	}
+	j.mu.Lock()
+	j.prune()
+	j.jobs[job.ID] = job
+	snapshot := *job
+	j.mu.Unlock()
+	logrus.Infof("Запуск переобогащения %s: %d человек", job.ID, len(ids))
+	go j.run(people, enrichers, job, ids)
+	return snapshot
+}
+
+// Get возвращает снимок состояния задачи
+func (j *Jobs) Get(id string) (JobStatus, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.prune()
+	job, ok := j.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return *job, true
+}
+
+// prune удаляет задачи, завершённые раньше retention; вызывается под мьютексом
+func (j *Jobs) prune() {
+	for id, job := range j.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > j.retention {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+// run переобогащает людей пачками по BatchSize, обновляя прогресс задачи
+func (j *Jobs) run(people repository.PersonRepository, enrichers *Registry, job *JobStatus, ids []uint) {
+	ctx := context.Background()
+	for start := 0; start < len(ids); start += BatchSize {
+		chunk := ids[start:min(start+BatchSize, len(ids))]
+		failed := reenrichChunk(ctx, people, enrichers, chunk)
+		j.mu.Lock()
+		job.Processed += len(chunk)
+		job.Failed += len(failed)
+		if len(failed) > 0 {
+			job.LastError = failed[len(failed)-1].Error()
+		}
+		j.mu.Unlock()
+		for _, err := range failed {
+			logrus.Warnf("Переобогащение %s: %v", job.ID, err)
+		}
+	}
+	now := time.Now()
+	j.mu.Lock()
+	job.Status = JobDone
+	job.FinishedAt = &now
+	j.mu.Unlock()
+	logrus.Infof("Переобогащение %s завершено: %d обработано, %d с ошибками", job.ID, job.Processed, job.Failed)
+}
+
+// reenrichChunk переобогащает людей с указанными ID и возвращает ошибки по тем, кого
+// не удалось переобогатить (в том числе удалённых после запуска задачи)
+func reenrichChunk(ctx context.Context, people repository.PersonRepository, enrichers *Registry, ids []uint) []error {
+	var failed []error
+	batch := make([]*models.Person, 0, len(ids))
+	for _, id := range ids {
+		person, err := people.Get(ctx, id)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("ID=%d: %w", id, err))
+			continue
+		}
+		batch = append(batch, person)
+	}
+	for i, err := range ReenrichBatch(ctx, people, enrichers, batch) {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("ID=%d: %w", batch[i].ID, err))
+		}
+	}
+	return failed
+}
+
+// newJobID генерирует случайный идентификатор задачи
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,74 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"person-api/models"
+	"person-api/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobsPrune проверяет, что завершённые задачи забываются через retention, а выполняющиеся — нет
+func TestJobsPrune(t *testing.T) {
+	jobs := NewJobs()
+	jobs.retention = time.Minute
+	old := time.Now().Add(-2 * time.Minute)
+	recent := time.Now()
+	jobs.jobs["old"] = &JobStatus{ID: "old", Status: JobDone, FinishedAt: &old}
+	jobs.jobs["recent"] = &JobStatus{ID: "recent", Status: JobDone, FinishedAt: &recent}
+	jobs.jobs["running"] = &JobStatus{ID: "running", Status: JobRunning, StartedAt: old}
+
+	_, ok := jobs.Get("old")
+	assert.False(t, ok)
+	_, ok = jobs.Get("recent")
+	assert.True(t, ok)
+	_, ok = jobs.Get("running")
+	assert.True(t, ok)
+	assert.Len(t, jobs.jobs, 2)
+}
+
+// failingStore хранилище, не сохраняющее результат обогащения одного человека
+type failingStore struct {
+	repository.PersonRepository
+	id uint
+}
+
+func (s failingStore) SaveEnrichment(ctx context.Context, before, after models.Person, status string) error {
+	if after.ID == s.id {
+		return errors.New("сбой записи")
+	}
+	return s.PersonRepository.SaveEnrichment(ctx, before, after, status)
+}
+
+// TestJobsCountsFailuresPerPerson проверяет, что ошибка одного человека не засчитывается всей пачке
+func TestJobsCountsFailuresPerPerson(t *testing.T) {
+	ctx := context.Background()
+	people := repository.NewMemoryPersonRepository()
+	var ids []uint
+	for _, name := range []string{"Дмитрий", "Иван", "Анна", "Пётр"} {
+		person := models.Person{Name: name}
+		require.NoError(t, people.Create(ctx, &person))
+		ids = append(ids, person.ID)
+	}
+	// Пётр удалён после запуска задачи
+	require.NoError(t, people.Delete(ctx, ids[3], 0))
+	enrichers := NewRegistry(funcEnricher{"gender", func(p *models.Person) error { p.Gender = "male"; return nil }})
+	jobs := NewJobs()
+
+	job := jobs.Start(failingStore{PersonRepository: people, id: ids[1]}, enrichers, nil, ids)
+	assert.Eventually(t, func() bool {
+		job, _ = jobs.Get(job.ID)
+		return job.Status == JobDone
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 4, job.Processed)
+	assert.Equal(t, 2, job.Failed)
+	for i, gender := range []string{"male", "", "male"} {
+		person, err := people.Get(ctx, ids[i])
+		require.NoError(t, err)
+		assert.Equal(t, gender, person.Gender)
+	}
+}
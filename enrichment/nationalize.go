@@ -0,0 +1,69 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"person-api/models"
+)
+
+// NationalizeURL адрес API Nationalize.io по умолчанию
+const NationalizeURL = "https://api.nationalize.io"
+
+// NationalizeResponse структура ответа от Nationalize.io
+type NationalizeResponse struct {
+	Name    string `json:"name"` // Запрошенное имя
+	Country []struct {
+		CountryID   string  `json:"country_id"`  // Код страны
+		Probability float64 `json:"probability"` // Вероятность
+	} `json:"country"`
+	Count int `json:"count"` // Размер выборки
+}
+
+func (r NationalizeResponse) requestedName() string {
+	return r.Name
+}
+
+// Nationalize определяет национальность по имени через Nationalize.io
+type Nationalize struct {
+	*Upstream
+}
+
+// NewNationalize создаёт провайдер Nationalize.io
+func NewNationalize(client *http.Client, cfg ProviderConfig) *Nationalize {
+	return &Nationalize{newUpstream("nationalize", client, cfg)}
+}
+
+// Fields возвращает заполняемые поля
+func (n *Nationalize) Fields() []string {
+	return []string{"nationality"}
+}
+
+// Enrich заполняет национальность, если вероятность самой вероятной страны выше порога
+func (n *Nationalize) Enrich(ctx context.Context, person *models.Person) error {
+	var resp NationalizeResponse
+	if err := n.getJSON(ctx, url.Values{"name": {person.Name}}, &resp); err != nil {
+		return err
+	}
+	n.apply(person, resp)
+	return nil
+}
+
+// EnrichBatch определяет национальность для нескольких людей пачками по BatchSize имён
+func (n *Nationalize) EnrichBatch(ctx context.Context, people []*models.Person) error {
+	return lookupBatch(ctx, n.Upstream, people, nil, n.apply)
+}
+
+// apply переносит ответ провайдера в person
+func (n *Nationalize) apply(person *models.Person, resp NationalizeResponse) {
+	if len(resp.Country) == 0 {
+		record(person, n.Name(), "nationality", "", nil, &resp.Count, false, resp)
+		return
+	}
+	top := resp.Country[0]
+	accepted := top.Probability > n.Config.Threshold
+	if accepted {
+		person.Nationality = top.CountryID
+	}
+	record(person, n.Name(), "nationality", top.CountryID, &top.Probability, &resp.Count, accepted, resp)
+}
@@ -0,0 +1,23 @@
+package enrichment
+
+import (
+	"encoding/json"
+	"person-api/models"
+	"time"
+)
+
+// record добавляет к человеку запись о происхождении значения поля.
+// resp — декодированный ответ провайдера, он сохраняется как сводка.
+func record(person *models.Person, provider, field, value string, probability *float64, count *int, accepted bool, resp interface{}) {
+	summary, _ := json.Marshal(resp)
+	person.Enrichments = append(person.Enrichments, models.PersonEnrichment{
+		Provider:    provider,
+		Field:       field,
+		Value:       value,
+		Probability: probability,
+		Count:       count,
+		Accepted:    accepted,
+		Summary:     string(summary),
+		LookedUpAt:  time.Now(),
+	})
+}
@@ -0,0 +1,26 @@
+package enrichment
+
+import (
+	"fmt"
+	"os"
+)
+
+// Mode режим обогащения при создании человека
+type Mode string
+
+const (
+	ModeSync  Mode = "sync"  // Обогащение внутри запроса POST /people
+	ModeAsync Mode = "async" // Запись сохраняется сразу, обогащение выполняют воркеры
+)
+
+// LoadMode читает режим обогащения из ENRICHMENT_MODE (по умолчанию sync)
+func LoadMode() (Mode, error) {
+	switch mode := Mode(os.Getenv("ENRICHMENT_MODE")); mode {
+	case "":
+		return ModeSync, nil
+	case ModeSync, ModeAsync:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим обогащения %q", mode)
+	}
+}
@@ -0,0 +1,116 @@
+package enrichment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaExhaustedError квота провайдера исчерпана до момента ResetAt
+type QuotaExhaustedError struct {
+	Provider string
+	ResetAt  time.Time
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	return fmt.Sprintf("%s: квота исчерпана до %s", e.Provider, e.ResetAt.Format(time.RFC3339))
+}
+
+// QuotaResetAt возвращает самое позднее время восстановления квоты, если err
+// (в том числе объединённая ошибка реестра) вызвана исчерпанием квоты
+func QuotaResetAt(err error) (time.Time, bool) {
+	var resetAt time.Time
+	found := false
+	var walk func(error)
+	walk = func(err error) {
+		var qe *QuotaExhaustedError
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				walk(e)
+			}
+		} else if errors.As(err, &qe) {
+			found = true
+			if qe.ResetAt.After(resetAt) {
+				resetAt = qe.ResetAt
+			}
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+	return resetAt, found
+}
+
+// QuotaStatus текущая квота провайдера по заголовкам X-Rate-Limit-*
+type QuotaStatus struct {
+	Provider  string     `json:"provider" example:"genderize"` // Имя провайдера
+	Known     bool       `json:"known"`                        // Получены ли заголовки квоты
+	Limit     int        `json:"limit" example:"1000"`         // Лимит запросов за период
+	Remaining int        `json:"remaining" example:"250"`      // Осталось запросов
+	ResetAt   *time.Time `json:"reset_at,omitempty"`           // Время восстановления квоты
+	Exhausted bool       `json:"exhausted"`                    // Квота исчерпана
+}
+
+// QuotaTracker отслеживает квоту провайдера по заголовкам ответов
+type QuotaTracker struct {
+	mu        sync.Mutex
+	known     bool
+	limit     int
+	remaining int
+	resetAt   time.Time
+}
+
+// Update обновляет квоту по заголовкам X-Rate-Limit-Limit, X-Rate-Limit-Remaining
+// и X-Rate-Limit-Reset (секунды до сброса); ответы без заголовков игнорируются
+func (q *QuotaTracker) Update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-Rate-Limit-Remaining"))
+	if err != nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.known = true
+	q.remaining = remaining
+	if limit, err := strconv.Atoi(header.Get("X-Rate-Limit-Limit")); err == nil {
+		q.limit = limit
+	}
+	if reset, err := strconv.Atoi(header.Get("X-Rate-Limit-Reset")); err == nil {
+		q.resetAt = time.Now().Add(time.Duration(reset) * time.Second)
+	}
+}
+
+// ExhaustUntil помечает квоту исчерпанной до resetAt (429 с долгим Retry-After без заголовков квоты)
+func (q *QuotaTracker) ExhaustUntil(resetAt time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.known = true
+	q.remaining = 0
+	q.resetAt = resetAt
+}
+
+// Exhausted сообщает, исчерпана ли квота, и когда она восстановится
+func (q *QuotaTracker) Exhausted() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.resetAt, q.exhausted()
+}
+
+// Status возвращает снимок квоты
+func (q *QuotaTracker) Status(provider string) QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	status := QuotaStatus{Provider: provider, Known: q.known, Limit: q.limit, Remaining: q.remaining, Exhausted: q.exhausted()}
+	if !q.resetAt.IsZero() {
+		resetAt := q.resetAt
+		status.ResetAt = &resetAt
+	}
+	return status
+}
+
+// exhausted вызывается под мьютексом
+func (q *QuotaTracker) exhausted() bool {
+	return q.known && q.remaining <= 0 && time.Now().Before(q.resetAt)
+}
@@ -0,0 +1,86 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"person-api/models"
+	"person-api/repository"
+)
+
+type refreshKey struct{}
+
+// WithRefresh помечает контекст как принудительное обновление: кэш пропускается,
+// но свежие ответы провайдеров в него записываются
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+// isRefresh сообщает, запрошено ли принудительное обновление
+func isRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// Select возвращает реестр только с указанными провайдерами (все, если имён нет)
+func (r *Registry) Select(names ...string) (*Registry, error) {
+	if len(names) == 0 {
+		return r, nil
+	}
+	selected := &Registry{budget: r.budget}
+	for _, name := range names {
+		found := false
+		for _, e := range r.enrichers {
+			if e.Name() == name {
+				selected.enrichers = append(selected.enrichers, e)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("неизвестный провайдер %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// Reenrich заново запускает обогащение уже сохранённого человека в обход кэша
+// и сохраняет изменившиеся поля и записи о происхождении
+func Reenrich(ctx context.Context, store repository.EnrichmentRepository, enrichers *Registry, person *models.Person) error {
+	return ReenrichBatch(ctx, store, enrichers, []*models.Person{person})[0]
+}
+
+// ReenrichBatch переобогащает нескольких людей за один проход провайдеров
+// (имена объединяются в пакетные запросы) и возвращает ошибки по каждому человеку
+// (nil — успешно). Ошибка провайдера относится ко всей пачке: все её люди получают
+// один и тот же статус; ошибка сохранения — только к своему человеку.
+func ReenrichBatch(ctx context.Context, store repository.EnrichmentRepository, enrichers *Registry, people []*models.Person) []error {
+	before := make([]models.Person, len(people))
+	for i, person := range people {
+		before[i] = *person
+	}
+	enrichErr := enrichers.EnrichBatch(WithRefresh(ctx), people)
+	status := models.EnrichmentDone
+	resetAt, quotaExhausted := QuotaResetAt(enrichErr)
+	switch {
+	case quotaExhausted:
+		// Дообогатим в фоне после сброса квоты
+		status = models.EnrichmentPending
+	case enrichErr != nil:
+		status = models.EnrichmentFailed
+	}
+	errs := make([]error, len(people))
+	for i, person := range people {
+		person.EnrichmentStatus = status
+		errs[i] = enrichErr
+		if err := store.SaveEnrichment(ctx, before[i], *person, status); err != nil {
+			errs[i] = err
+			continue
+		}
+		if quotaExhausted {
+			if err := store.Enqueue(ctx, person.ID, resetAt); err != nil {
+				errs[i] = err
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,145 @@
+package enrichment
+
+import (
+	"context"
+	"person-api/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// RulesName имя офлайн-обогатителя по правилам
+const RulesName = "rules"
+
+// Вероятности, с которыми правила определяют пол
+const (
+	patronymicProbability = 0.99 // Отчество почти однозначно задаёт пол
+	surnameProbability    = 0.9  // Окончание фамилии надёжно только для русских фамилий
+)
+
+// genderRule правило «окончание слова → пол»
+type genderRule struct {
+	suffix string
+	gender string
+}
+
+// patronymicRules окончания отчеств (кириллица и транслитерация)
+var patronymicRules = []genderRule{
+	{"ович", "male"}, {"евич", "male"}, {"ич", "male"},
+	{"овна", "female"}, {"евна", "female"}, {"ична", "female"},
+	{"ovich", "male"}, {"evich", "male"}, {"ich", "male"},
+	{"ovna", "female"}, {"evna", "female"}, {"ichna", "female"},
+}
+
+// patronymicParticles тюркские отчества пишутся отдельным словом: «Мамед оглы», «Ахмед кызы»
+var patronymicParticles = map[string]string{
+	"оглы": "male", "улы": "male", "кызы": "female", "гызы": "female",
+	"ogly": "male", "oglu": "male", "uly": "male", "kyzy": "female", "gyzy": "female", "qizi": "female",
+}
+
+// surnameRules окончания русских фамилий. Латинские -in/-ina не используются:
+// они часто встречаются в нерусских фамилиях любого пола (Martin, Molina).
+var surnameRules = []genderRule{
+	{"ов", "male"}, {"ев", "male"}, {"ин", "male"}, {"ын", "male"}, {"ский", "male"}, {"цкий", "male"}, {"ской", "male"},
+	{"ова", "female"}, {"ева", "female"}, {"ина", "female"}, {"ына", "female"}, {"ская", "female"}, {"цкая", "female"},
+	{"ov", "male"}, {"ev", "male"}, {"off", "male"}, {"sky", "male"}, {"skiy", "male"}, {"skii", "male"}, {"skij", "male"},
+	{"ova", "female"}, {"eva", "female"}, {"skaya", "female"}, {"skaia", "female"}, {"skaja", "female"},
+}
+
+// minStem минимальная длина основы перед окончанием, чтобы не срабатывать на коротких словах
+const minStem = 2
+
+// RuleMatch сработавшее правило; сохраняется как сводка в происхождении
+type RuleMatch struct {
+	Source string `json:"source"` // patronymic или surname
+	Suffix string `json:"suffix"` // Совпавшее окончание
+	Input  string `json:"input"`  // Проверенное значение поля
+}
+
+// Rules определяет пол по окончаниям отчества и фамилии без обращения к сети.
+// Отчество надёжнее фамилии и проверяется первым.
+type Rules struct{}
+
+// NewRules создаёт обогатитель по правилам
+func NewRules() *Rules {
+	return &Rules{}
+}
+
+// LoadRulesEnabled читает ENRICHMENT_RULES (по умолчанию правила включены)
+func LoadRulesEnabled() bool {
+	return boolFromEnv("ENRICHMENT_RULES", true)
+}
+
+// Name возвращает имя обогатителя
+func (r *Rules) Name() string {
+	return RulesName
+}
+
+// Offline сообщает, что правила работают без сети и запускаются до удалённых провайдеров
+func (r *Rules) Offline() bool {
+	return true
+}
+
+// Fields возвращает заполняемые поля
+func (r *Rules) Fields() []string {
+	return []string{"gender"}
+}
+
+// Enrich заполняет пол по первому сработавшему правилу: сначала отчество, затем фамилия
+func (r *Rules) Enrich(ctx context.Context, person *models.Person) error {
+	match, gender, probability, ok := matchGender(person)
+	if !ok {
+		return nil
+	}
+	person.Gender = gender
+	record(person, RulesName, "gender", gender, &probability, nil, true, match)
+	return nil
+}
+
+// matchGender ищет правило для отчества, а если его нет — для фамилии
+func matchGender(person *models.Person) (RuleMatch, string, float64, bool) {
+	if word := lastWord(person.Patronymic); word != "" {
+		if gender, ok := patronymicParticles[word]; ok {
+			return RuleMatch{Source: "patronymic", Suffix: word, Input: person.Patronymic}, gender, patronymicProbability, true
+		}
+		if rule, ok := matchSuffix(word, patronymicRules); ok {
+			return RuleMatch{Source: "patronymic", Suffix: rule.suffix, Input: person.Patronymic}, rule.gender, patronymicProbability, true
+		}
+	}
+	if word := lastWord(person.Surname); word != "" {
+		if rule, ok := matchSuffix(word, surnameRules); ok {
+			return RuleMatch{Source: "surname", Suffix: rule.suffix, Input: person.Surname}, rule.gender, surnameProbability, true
+		}
+	}
+	return RuleMatch{}, "", 0, false
+}
+
+// matchSuffix возвращает правило с самым длинным совпавшим окончанием
+// (-ова важнее -ов), если основа перед ним не короче minStem
+func matchSuffix(word string, rules []genderRule) (genderRule, bool) {
+	var best genderRule
+	found := false
+	for _, rule := range rules {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+		if utf8.RuneCountInString(word)-utf8.RuneCountInString(rule.suffix) < minStem {
+			continue
+		}
+		if !found || len(rule.suffix) > len(best.suffix) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// lastWord возвращает последнее слово в нижнем регистре с ё, заменённой на е
+// («Мамед оглы» → «оглы», «Римский-Корсаков» → «корсаков»)
+func lastWord(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ReplaceAll(words[len(words)-1], "ё", "е")
+}
@@ -0,0 +1,91 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"person-api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRules проверяет определение пола по отчеству и фамилии
+func TestRules(t *testing.T) {
+	cases := []struct {
+		surname, patronymic string
+		gender, source      string
+	}{
+		{"Ушаков", "Васильевич", "male", "patronymic"},
+		{"Ушакова", "Васильевна", "female", "patronymic"},
+		{"Ульянов", "Ильич", "male", "patronymic"},
+		{"Фомина", "Кузьминична", "female", "patronymic"},
+		{"Алиев", "Мамед оглы", "male", "patronymic"},
+		{"Ivanova", "Sergeevna", "female", "patronymic"},
+		{"Petrov", "Ivanovich", "male", "patronymic"},
+		// Отчество важнее фамилии
+		{"Иванова", "Петрович", "male", "patronymic"},
+		{"Королёва", "", "female", "surname"},
+		{"Достоевский", "", "male", "surname"},
+		{"Римская-Корсакова", "", "female", "surname"},
+		{"Smirnova", "", "female", "surname"},
+		{"Kandinsky", "", "male", "surname"},
+		{"Smith", "", "", ""},
+		{"Molina", "", "", ""},
+		{"Ов", "", "", ""},
+	}
+	rules := NewRules()
+	for _, tc := range cases {
+		person := models.Person{Name: "Саша", Surname: tc.surname, Patronymic: tc.patronymic}
+		assert.NoError(t, rules.Enrich(context.Background(), &person))
+		assert.Equal(t, tc.gender, person.Gender, tc.surname+" "+tc.patronymic)
+		if tc.gender == "" {
+			assert.Empty(t, person.Enrichments)
+			continue
+		}
+		if assert.Len(t, person.Enrichments, 1) {
+			rec := person.Enrichments[0]
+			assert.Equal(t, RulesName, rec.Provider)
+			assert.True(t, rec.Accepted)
+			assert.Contains(t, rec.Summary, `"source":"`+tc.source+`"`)
+		}
+	}
+}
+
+// TestRulesSkipRemote проверяет, что пол по правилам не запрашивается у Genderize
+func TestRulesSkipRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос к провайдеру: %s", r.URL)
+	}))
+	defer srv.Close()
+	r := NewRegistry(
+		NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7}),
+		NewRules(),
+	)
+	assert.Equal(t, [][]int{{1}, {0}}, r.stages())
+	person := models.Person{Name: "Саша", Surname: "Ушакова", Patronymic: "Васильевна"}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "female", person.Gender)
+	assert.Len(t, person.Enrichments, 1)
+}
+
+// TestClientFieldsSkipEnrichers проверяет, что указанные клиентом поля не запрашиваются и не перезаписываются
+func TestClientFieldsSkipEnrichers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("неожиданный запрос к провайдеру: %s", r.URL)
+	}))
+	defer srv.Close()
+	r := NewRegistry(
+		NewRules(),
+		NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7}),
+		// Обогатитель без Fields вызывается, но клиентское значение не трогает
+		funcEnricher{"any", func(p *models.Person) error { p.Gender = "male"; p.Nationality = "RU"; return nil }},
+	)
+	person := models.Person{Name: "Саша", Surname: "Иванов", Gender: "female", GenderSource: models.SourceClient}
+	assert.NoError(t, r.Enrich(context.Background(), &person))
+	assert.Equal(t, "female", person.Gender)
+	assert.Equal(t, models.SourceClient, person.GenderSource)
+	assert.Equal(t, "RU", person.Nationality)
+	assert.Equal(t, models.SourceProvider, person.NationalitySource)
+	assert.Empty(t, person.Enrichments)
+}
@@ -0,0 +1,182 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"person-api/models"
+	"person-api/repository"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// WorkerConfig настройки пула фоновых воркеров
+type WorkerConfig struct {
+	Workers      int           // Количество воркеров
+	PollInterval time.Duration // Пауза между опросами пустой очереди
+	MaxAttempts  int           // Максимум попыток до статуса failed
+	RetryDelay   time.Duration // Базовая задержка повтора (удваивается с каждой попыткой)
+}
+
+// DefaultWorkerConfig настройки воркеров по умолчанию
+var DefaultWorkerConfig = WorkerConfig{Workers: 2, PollInterval: time.Second, MaxAttempts: 5, RetryDelay: 10 * time.Second}
+
+// LoadWorkerConfig читает настройки воркеров из ENRICHMENT_WORKERS,
+// ENRICHMENT_POLL_INTERVAL, ENRICHMENT_MAX_ATTEMPTS и ENRICHMENT_RETRY_DELAY
+func LoadWorkerConfig() WorkerConfig {
+	cfg := DefaultWorkerConfig
+	cfg.Workers = intFromEnv("ENRICHMENT_WORKERS", cfg.Workers)
+	cfg.PollInterval = durationFromEnv("ENRICHMENT_POLL_INTERVAL", cfg.PollInterval)
+	cfg.MaxAttempts = intFromEnv("ENRICHMENT_MAX_ATTEMPTS", cfg.MaxAttempts)
+	cfg.RetryDelay = durationFromEnv("ENRICHMENT_RETRY_DELAY", cfg.RetryDelay)
+	return cfg
+}
+
+// WorkerPool обрабатывает задачи из очереди enrichment_jobs
+type WorkerPool struct {
+	db        *gorm.DB
+	people    repository.PersonRepository
+	enrichers *Registry
+	cfg       WorkerConfig
+}
+
+// NewWorkerPool создаёт пул воркеров
+func NewWorkerPool(db *gorm.DB, enrichers *Registry, cfg WorkerConfig) *WorkerPool {
+	return &WorkerPool{db: db, people: repository.NewGormPersonRepository(db), enrichers: enrichers, cfg: cfg}
+}
+
+// Run запускает воркеры и блокируется до отмены ctx
+func (p *WorkerPool) Run(ctx context.Context) {
+	// Задачи, зависшие в running после падения процесса, возвращаем в очередь
+	if err := p.db.Model(&models.EnrichmentJob{}).
+		Where("status = ?", models.EnrichmentRunning).
+		Update("status", models.EnrichmentPending).Error; err != nil {
+		logrus.Errorf("Ошибка восстановления очереди обогащения: %v", err)
+	}
+	logrus.Infof("Запуск воркеров обогащения: %d", p.cfg.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < p.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop обрабатывает задачи, пока очередь не пуста, затем ждёт PollInterval
+func (p *WorkerPool) loop(ctx context.Context) {
+	for {
+		processed, err := p.ProcessNext(ctx)
+		if err != nil {
+			logrus.Errorf("Ошибка обработки очереди обогащения: %v", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.cfg.PollInterval):
+		}
+	}
+}
+
+// ProcessNext берёт одну готовую задачу из очереди и выполняет её.
+// Возвращает false, если готовых задач нет.
+func (p *WorkerPool) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := p.claim()
+	if err != nil || job == nil {
+		return false, err
+	}
+	found, err := p.people.Get(ctx, job.PersonID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Человека удалили, пока задача ждала в очереди
+			return true, p.finish(job, models.EnrichmentFailed, "человек не найден")
+		}
+		return true, err
+	}
+	person := *found
+	before := person
+	enrichErr := p.enrichers.Enrich(ctx, &person)
+	if resetAt, ok := QuotaResetAt(enrichErr); ok {
+		// Исчерпанная квота — не ошибка задачи: сохраняем что есть и ждём сброса, не тратя попытку
+		if err := p.people.SaveEnrichment(ctx, before, person, ""); err != nil {
+			return true, err
+		}
+		logrus.Infof("Обогащение ID=%d отложено до сброса квоты: %s", person.ID, resetAt.Format(time.RFC3339))
+		return true, p.db.Model(job).Updates(map[string]interface{}{
+			"status":     models.EnrichmentPending,
+			"attempts":   gorm.Expr("attempts - 1"),
+			"last_error": enrichErr.Error(),
+			"run_after":  resetAt,
+		}).Error
+	}
+	status := models.EnrichmentDone
+	lastError := ""
+	if enrichErr != nil {
+		lastError = enrichErr.Error()
+		status = models.EnrichmentFailed
+		if job.Attempts < p.cfg.MaxAttempts {
+			status = models.EnrichmentPending
+		}
+	}
+	saveStatus := ""
+	if status != models.EnrichmentPending {
+		saveStatus = status
+	}
+	if err := p.people.SaveEnrichment(ctx, before, person, saveStatus); err != nil {
+		return true, err
+	}
+	if status == models.EnrichmentPending {
+		delay := p.cfg.RetryDelay << (job.Attempts - 1)
+		logrus.Warnf("Обогащение ID=%d не удалось (попытка %d), повтор через %s: %v", person.ID, job.Attempts, delay, enrichErr)
+		return true, p.db.Model(job).Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": lastError,
+			"run_after":  time.Now().Add(delay),
+		}).Error
+	}
+	logrus.Infof("Обогащение ID=%d завершено: %s", person.ID, status)
+	return true, p.finish(job, status, lastError)
+}
+
+// claim атомарно забирает первую готовую задачу из очереди.
+// Захват через условный UPDATE работает и в Postgres, и в SQLite.
+func (p *WorkerPool) claim() (*models.EnrichmentJob, error) {
+	for {
+		var job models.EnrichmentJob
+		err := p.db.Where("status = ? AND run_after <= ?", models.EnrichmentPending, time.Now()).
+			Order("run_after, id").Limit(1).Find(&job).Error
+		if err != nil || job.ID == 0 {
+			return nil, err
+		}
+		res := p.db.Model(&models.EnrichmentJob{}).
+			Where("id = ? AND status = ?", job.ID, models.EnrichmentPending).
+			Updates(map[string]interface{}{
+				"status":   models.EnrichmentRunning,
+				"attempts": gorm.Expr("attempts + 1"),
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			job.Status = models.EnrichmentRunning
+			job.Attempts++
+			return &job, nil
+		}
+		// Задачу забрал другой воркер — пробуем следующую
+	}
+}
+
+// finish переводит задачу в конечный статус
+func (p *WorkerPool) finish(job *models.EnrichmentJob, status, lastError string) error {
+	return p.db.Model(job).Updates(map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+	}).Error
+}
@@ -0,0 +1,102 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"person-api/models"
+	"person-api/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupQueue создаёт базу в памяти с одним человеком в очереди
+func setupQueue(t *testing.T) (*gorm.DB, models.Person) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Person{}, &models.EnrichmentJob{}, &models.PersonEnrichment{}, &models.PersonVersion{}))
+	person := models.Person{Name: "Дмитрий", Surname: "Ушаков", EnrichmentStatus: models.EnrichmentPending}
+	require.NoError(t, db.Create(&person).Error)
+	require.NoError(t, repository.NewGormPersonRepository(db).Enqueue(context.Background(), person.ID, time.Now().Add(-time.Second)))
+	return db, person
+}
+
+// TestWorkerProcessNext проверяет успешную обработку задачи
+func TestWorkerProcessNext(t *testing.T) {
+	db, person := setupQueue(t)
+	srv := newTestServer(t, `{"gender":"male","probability":0.99,"count":100}`)
+	enrichers := NewRegistry(NewGenderize(srv.Client(), ProviderConfig{BaseURL: srv.URL, Threshold: 0.7}))
+	pool := NewWorkerPool(db, enrichers, DefaultWorkerConfig)
+
+	processed, err := pool.ProcessNext(context.Background())
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	var saved models.Person
+	require.NoError(t, db.First(&saved, person.ID).Error)
+	assert.Equal(t, "male", saved.Gender)
+	assert.Equal(t, models.EnrichmentDone, saved.EnrichmentStatus)
+	// Происхождение сохранено в person_enrichments
+	var records []models.PersonEnrichment
+	require.NoError(t, db.Where("person_id = ?", person.ID).Find(&records).Error)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "genderize", records[0].Provider)
+		assert.InDelta(t, 0.99, *records[0].Probability, 1e-9)
+	}
+
+	status, err := repository.NewGormPersonRepository(db).EnrichmentStatus(context.Background(), saved)
+	require.NoError(t, err)
+	assert.Equal(t, models.EnrichmentDone, status.Status)
+	assert.Equal(t, 1, status.Attempts)
+
+	// Очередь пуста
+	processed, err = pool.ProcessNext(context.Background())
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
+
+// TestWorkerRetriesAndFails проверяет повторы и перевод в failed после исчерпания попыток
+func TestWorkerRetriesAndFails(t *testing.T) {
+	db, person := setupQueue(t)
+	enrichers := NewRegistry(funcEnricher{"broken", func(*models.Person) error { return errors.New("недоступен") }})
+	pool := NewWorkerPool(db, enrichers, WorkerConfig{Workers: 1, MaxAttempts: 2, RetryDelay: 0})
+
+	for i := 0; i < 2; i++ {
+		processed, err := pool.ProcessNext(context.Background())
+		require.NoError(t, err)
+		assert.True(t, processed)
+	}
+	status, err := repository.NewGormPersonRepository(db).EnrichmentStatus(context.Background(), person)
+	require.NoError(t, err)
+	assert.Equal(t, models.EnrichmentFailed, status.Status)
+	assert.Equal(t, 2, status.Attempts)
+	assert.Contains(t, status.LastError, "недоступен")
+
+	var saved models.Person
+	require.NoError(t, db.First(&saved, person.ID).Error)
+	assert.Equal(t, models.EnrichmentFailed, saved.EnrichmentStatus)
+}
+
+// TestReenrichThenCreate проверяет, что переобогащение без изменений не портит кэш:
+// следующий человек с тем же именем получает значение из кэша
+func TestReenrichThenCreate(t *testing.T) {
+	db, person := setupQueue(t)
+	require.NoError(t, db.Model(&person).Update("gender", "male").Error)
+	person.Gender = "male"
+	inner := &countingEnricher{}
+	enrichers := NewRegistry(NewNameCache(10, time.Hour, nil).Wrap(inner))
+
+	require.NoError(t, Reenrich(context.Background(), repository.NewGormPersonRepository(db), enrichers, &person))
+	next := models.Person{Name: "Дмитрий"}
+	require.NoError(t, enrichers.Enrich(context.Background(), &next))
+
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "male", next.Gender)
+	if assert.Len(t, next.Enrichments, 1) {
+		assert.True(t, next.Enrichments[0].Cached)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"person-api/enrichment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminTokenHeader заголовок с токеном администратора
+const AdminTokenHeader = "X-Admin-Token"
+
+// isAdmin проверяет токен администратора в запросе
+func isAdmin(c *gin.Context, token string) bool {
+	given := c.GetHeader(AdminTokenHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// AdminAuth пропускает только запросы с верным токеном администратора
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c, token) {
+			logrus.Warnf("Отказ в доступе к %s", c.FullPath())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Требуется токен администратора"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CachePurgeResponse результат очистки кэша
+type CachePurgeResponse struct {
+	Purged int64 `json:"purged" example:"3"` // Количество удалённых записей
+}
+
+// @Summary Статистика кэша обогащения
+// @Description Возвращает количество попаданий, промахов и записей в памяти
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Success 200 {object} enrichment.CacheStats
+// @Failure 401 {object} models.ErrorResponse
+// @Router /admin/enrichment/cache [get]
+func GetCacheStats(cache *enrichment.NameCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, cache.Stats())
+	}
+}
+
+// @Summary Очистка кэша обогащения
+// @Description Удаляет записи кэша для имени у всех провайдеров или весь кэш, если имя не указано
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Param name query string false "Имя"
+// @Success 200 {object} CachePurgeResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/enrichment/cache [delete]
+func PurgeCache(cache *enrichment.NameCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("name")
+		purged, err := cache.Purge(c.Request.Context(), name)
+		if err != nil {
+			logrus.Errorf("Ошибка очистки кэша: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось очистить кэш"})
+			return
+		}
+		logrus.Infof("Очищен кэш обогащения (имя %q): %d записей", name, purged)
+		c.JSON(http.StatusOK, CachePurgeResponse{Purged: purged})
+	}
+}
+
+// @Summary Квоты провайдеров обогащения
+// @Description Возвращает лимиты из заголовков X-Rate-Limit-* последних ответов провайдеров
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Success 200 {array} enrichment.QuotaStatus
+// @Failure 401 {object} models.ErrorResponse
+// @Router /admin/enrichment/quota [get]
+func GetQuotas(enrichers *enrichment.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, enrichers.Quotas())
+	}
+}
+
+// @Summary Состояние справочника имён
+// @Description Возвращает источник, количество имён и время загрузки офлайн-справочника
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Success 200 {object} enrichment.DatasetStatus
+// @Failure 401 {object} models.ErrorResponse
+// @Router /admin/enrichment/dataset [get]
+func GetDatasetStatus(dataset *enrichment.DatasetEnricher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, dataset.Status())
+	}
+}
+
+// @Summary Перечитать справочник имён
+// @Description Заново загружает справочник из ENRICHMENT_DATASET (например, после dataset import).
+// @Description При ошибке продолжает работать прежний справочник.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Success 200 {object} enrichment.DatasetStatus
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/enrichment/dataset/reload [post]
+func ReloadDataset(dataset *enrichment.DatasetEnricher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := dataset.Reload(); err != nil {
+			logrus.Errorf("Ошибка загрузки справочника: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		status := dataset.Status()
+		logrus.Infof("Справочник имён перечитан: %d имён", status.Names)
+		c.JSON(http.StatusOK, status)
+	}
+}
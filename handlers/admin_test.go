@@ -0,0 +1,53 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "person-api/enrichment"
+)
+
+// setupAdminRouter создаёт роутер с административными маршрутами
+func setupAdminRouter(cache *enrichment.NameCache) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    r := gin.Default()
+    admin := r.Group("/admin", AdminAuth("secret"))
+    admin.GET("/enrichment/cache", GetCacheStats(cache))
+    admin.DELETE("/enrichment/cache", PurgeCache(cache))
+    return r
+}
+
+// TestAdminAuth тестирует отказ без токена администратора
+func TestAdminAuth(t *testing.T) {
+    r := setupAdminRouter(enrichment.NewNameCache(10, time.Hour, nil))
+    req, _ := http.NewRequest("GET", "/admin/enrichment/cache", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+    req.Header.Set(AdminTokenHeader, "wrong")
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestPurgeCache тестирует очистку кэша по имени
+func TestPurgeCache(t *testing.T) {
+    cache := enrichment.NewNameCache(10, time.Hour, nil)
+    cache.Set(context.Background(), enrichment.CacheEntry{Provider: "genderize", Name: "Дмитрий", Gender: "male"})
+    cache.Set(context.Background(), enrichment.CacheEntry{Provider: "genderize", Name: "Иван", Gender: "male"})
+    r := setupAdminRouter(cache)
+    req, _ := http.NewRequest("DELETE", "/admin/enrichment/cache?name=дмитрий", nil)
+    req.Header.Set(AdminTokenHeader, "secret")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response CachePurgeResponse
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, int64(1), response.Purged)
+    assert.Equal(t, 1, cache.Stats().Entries)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControlConfig директивы Cache-Control для маршрутов чтения (пусто — заголовок не отдаётся)
+type CacheControlConfig struct {
+	Person  string // GET /people/:id
+	People  string // GET /people
+	History string // GET /people/:id/history
+}
+
+// DefaultCacheControl по умолчанию ответы можно хранить только клиенту и только с перепроверкой
+// (условный запрос с If-None-Match вернёт 304, если данные не изменились)
+var DefaultCacheControl = CacheControlConfig{
+	Person:  "private, no-cache",
+	People:  "private, no-cache",
+	History: "private, no-cache",
+}
+
+// LoadCacheControlConfig читает CACHE_CONTROL_PERSON, CACHE_CONTROL_PEOPLE и CACHE_CONTROL_HISTORY.
+// Заданная пустой переменная отключает заголовок для маршрута.
+func LoadCacheControlConfig() CacheControlConfig {
+	cfg := DefaultCacheControl
+	lookup := func(key string, value *string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*value = v
+		}
+	}
+	lookup("CACHE_CONTROL_PERSON", &cfg.Person)
+	lookup("CACHE_CONTROL_PEOPLE", &cfg.People)
+	lookup("CACHE_CONTROL_HISTORY", &cfg.History)
+	return cfg
+}
+
+// cacheControlKey ключ контекста запроса с директивами Cache-Control маршрута
+const cacheControlKey = "cache_control"
+
+// CacheControl задаёт директивы Cache-Control для маршрута. Заголовок отдаётся только
+// с успешными и 304 ответами, чтобы прокси не сохраняли ошибки.
+func CacheControl(directives string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cacheControlKey, directives)
+		c.Next()
+	}
+}
+
+// setCacheControl отдаёт заголовок Cache-Control, заданный для маршрута
+func setCacheControl(c *gin.Context) {
+	if directives := c.GetString(cacheControlKey); directives != "" {
+		c.Header("Cache-Control", directives)
+	}
+}
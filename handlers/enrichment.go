@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"person-api/enrichment"
+	"person-api/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary Статус обогащения человека
+// @Description Возвращает статус фонового обогащения, количество попыток и последнюю ошибку
+// @Tags enrichment
+// @Produce json
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Success 200 {object} models.EnrichmentStatus
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/{id}/enrichment [get]
+func GetEnrichmentStatus(people repository.PersonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		// Ищем запись
+		person, err := people.Get(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err, "Не удалось получить")
+			return
+		}
+		status, err := people.EnrichmentStatus(c.Request.Context(), *person)
+		if err != nil {
+			logrus.Errorf("Ошибка получения статуса обогащения ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить статус"})
+			return
+		}
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+// @Summary Переобогатить человека
+// @Description Заново запускает обогащение в обход кэша и сохраняет обновлённые данные.
+// @Description Параметр provider (можно несколько) ограничивает набор провайдеров.
+// @Tags enrichment
+// @Produce json
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param provider query []string false "Провайдеры (genderize, nationalize, agify)" collectionFormat(multi)
+// @Success 200 {object} models.Person
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/{id}/enrich [post]
+func EnrichPerson(people repository.PersonRepository, enrichers *enrichment.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		selected, err := enrichers.Select(c.QueryArray("provider")...)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		// Ищем запись
+		person, err := people.Get(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err, "Не удалось получить")
+			return
+		}
+		if err := enrichment.Reenrich(c.Request.Context(), people, selected, person); err != nil {
+			logrus.Warnf("Переобогащение ID=%d выполнено частично: %v", id, err)
+		}
+		if person, err = people.Get(c.Request.Context(), id); err != nil {
+			logrus.Errorf("Ошибка чтения ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+			return
+		}
+		logrus.Infof("Переобогащён ID: %d", id)
+		c.JSON(http.StatusOK, person)
+	}
+}
+
+// @Summary Массовое переобогащение
+// @Description Запускает фоновую задачу переобогащения людей, отобранных теми же фильтрами, что и GET /people.
+// @Description skip и limit применяются, только если указаны явно. Прогресс — GET /enrichment/jobs/{id}.
+// @Tags enrichment
+// @Produce json
+// @Param name query string false "Фильтр по имени"
+// @Param surname query string false "Фильтр по фамилии"
+// @Param age query int false "Фильтр по возрасту"
+// @Param gender query string false "Фильтр по полу"
+// @Param nationality query string false "Фильтр по национальности"
+// @Param skip query int false "Смещение (пагинация)" minimum(0)
+// @Param limit query int false "Ограничение (пагинация)" minimum(1)
+// @Param provider query []string false "Провайдеры (genderize, nationalize, agify)" collectionFormat(multi)
+// @Success 202 {object} enrichment.JobStatus
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/enrich [post]
+func EnrichPeople(people repository.PersonRepository, enrichers *enrichment.Registry, jobs *enrichment.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		providers := c.QueryArray("provider")
+		selected, err := enrichers.Select(providers...)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter, ok := personFilter(c)
+		if !ok {
+			return
+		}
+		if filter.Offset, ok = queryInt(c, "skip", 0, 0); !ok {
+			return
+		}
+		// Без limit переобогащаются все подходящие люди
+		if filter.Limit, ok = queryInt(c, "limit", 0, 1); !ok {
+			return
+		}
+		matched, err := people.List(c.Request.Context(), filter)
+		if err != nil {
+			logrus.Errorf("Ошибка отбора людей для переобогащения: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+			return
+		}
+		ids := make([]uint, len(matched))
+		for i, person := range matched {
+			ids[i] = person.ID
+		}
+		job := jobs.Start(people, selected, providers, ids)
+		c.Header("Location", "/enrichment/jobs/"+job.ID)
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// @Summary Статус массового переобогащения
+// @Description Возвращает прогресс фоновой задачи переобогащения. Завершённые задачи хранятся час, затем 404.
+// @Tags enrichment
+// @Produce json
+// @Param id path string true "ID задачи"
+// @Success 200 {object} enrichment.JobStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Router /enrichment/jobs/{id} [get]
+func GetEnrichmentJob(jobs *enrichment.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, ok := jobs.Get(c.Param("id"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Задача не найдена"})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
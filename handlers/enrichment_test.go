@@ -0,0 +1,75 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "person-api/enrichment"
+    "person-api/models"
+    "person-api/repository"
+)
+
+// TestEnrichPerson тестирует переобогащение одного человека
+func TestEnrichPerson(t *testing.T) {
+    r, db := setupRouter()
+    people := repository.NewGormPersonRepository(db)
+    people.Create(context.Background(), &models.Person{Name: "Дмитрий", Surname: "Ушаков"})
+    req, _ := http.NewRequest("POST", "/people/1/enrich?provider=fake", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, "male", person.Gender)
+    assert.Equal(t, models.EnrichmentDone, person.EnrichmentStatus)
+    // Происхождение сохранено, изменение записано версией обогащения
+    records, _ := people.Enrichments(context.Background(), 1)
+    assert.Len(t, records, 1)
+    versions, _ := people.History(context.Background(), 1)
+    if assert.Len(t, versions, 2) {
+        assert.Equal(t, repository.EnrichmentActor, versions[1].Actor)
+    }
+}
+
+// TestEnrichPersonUnknownProvider тестирует отказ для неизвестного провайдера
+func TestEnrichPersonUnknownProvider(t *testing.T) {
+    r, db := setupRouter()
+    repository.NewGormPersonRepository(db).Create(context.Background(), &models.Person{Name: "Дмитрий", Surname: "Ушаков"})
+    req, _ := http.NewRequest("POST", "/people/1/enrich?provider=unknown", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestEnrichPeople тестирует массовое переобогащение с отслеживанием прогресса
+func TestEnrichPeople(t *testing.T) {
+    r, db := setupRouter()
+    people := repository.NewGormPersonRepository(db)
+    people.Create(context.Background(), &models.Person{Name: "Дмитрий", Surname: "Ушаков", Nationality: "RU"})
+    people.Create(context.Background(), &models.Person{Name: "Иван", Surname: "Петров", Nationality: "RU"})
+    people.Create(context.Background(), &models.Person{Name: "John", Surname: "Smith", Nationality: "US"})
+    req, _ := http.NewRequest("POST", "/people/enrich?nationality=RU", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusAccepted, w.Code)
+    var job enrichment.JobStatus
+    json.Unmarshal(w.Body.Bytes(), &job)
+    assert.Equal(t, 2, job.Total)
+    assert.Equal(t, "/enrichment/jobs/"+job.ID, w.Header().Get("Location"))
+    // Ждём завершения фоновой задачи
+    assert.Eventually(t, func() bool {
+        req, _ := http.NewRequest("GET", "/enrichment/jobs/"+job.ID, nil)
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        json.Unmarshal(w.Body.Bytes(), &job)
+        return job.Status == enrichment.JobDone
+    }, time.Second, 10*time.Millisecond)
+    assert.Equal(t, 2, job.Processed)
+    assert.Equal(t, 0, job.Failed)
+    untouched, _ := people.Get(context.Background(), 3)
+    assert.Empty(t, untouched.Gender)
+}
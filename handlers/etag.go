@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"person-api/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// personETag возвращает ETag человека: номер версии записи
+func personETag(person *models.Person) string {
+	return `"` + strconv.Itoa(person.Version) + `"`
+}
+
+// enrichmentETag возвращает ETag человека вместе с записями о происхождении (include=enrichment):
+// обогащение может добавить записи, не изменив полей, и тогда версия записи не растёт
+func enrichmentETag(person *models.Person) string {
+	var last uint
+	for _, rec := range person.Enrichments {
+		last = max(last, rec.ID)
+	}
+	return fmt.Sprintf(`"%d-%d-%d"`, person.Version, len(person.Enrichments), last)
+}
+
+// setPersonETag отдаёт ETag человека в заголовке ответа
+func setPersonETag(c *gin.Context, person *models.Person) {
+	c.Header("ETag", personETag(person))
+}
+
+// listETag возвращает слабый ETag списка: от параметров запроса (фильтр и пагинация),
+// количества подходящих записей и времени последнего изменения среди них
+func listETag(query url.Values, total int64, modified time.Time) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%d|%d", query.Encode(), total, modified.UnixNano())
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// etagMatches проверяет, есть ли etag в списке заголовка If-Match или If-None-Match (* — любой).
+// При строгом сравнении (If-Match) слабые ETag с W/ не совпадают ни с чем.
+func etagMatches(header, etag string, weak bool) bool {
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch проверяет заголовок If-Match: без заголовка или с * подходит любая версия,
+// иначе один из перечисленных ETag должен совпасть с текущим (слабые W/ не совпадают)
+func ifMatch(c *gin.Context, person *models.Person) bool {
+	header := c.GetHeader("If-Match")
+	return header == "" || etagMatches(header, personETag(person), false)
+}
+
+// notModified отдаёт ETag и Last-Modified и проверяет, актуальна ли копия клиента:
+// по If-None-Match, а без него — по If-Modified-Since. Если актуальна, отвечает 304.
+func notModified(c *gin.Context, etag string, modified time.Time) bool {
+	setCacheControl(c)
+	c.Header("ETag", etag)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		if !etagMatches(header, etag, true) {
+			return false
+		}
+	} else {
+		// Last-Modified передаётся с точностью до секунды
+		since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+		if err != nil || modified.IsZero() || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// RequireIfMatch при required отклоняет изменяющие запросы без заголовка If-Match
+// (428 Precondition Required); иначе If-Match проверяется, только если передан
+func RequireIfMatch(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if required && c.GetHeader("If-Match") == "" {
+			c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "Требуется заголовок If-Match с ETag записи"})
+			return
+		}
+		c.Next()
+	}
+}
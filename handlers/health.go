@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"person-api/enrichment"
+	"person-api/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HealthResponse состояние сервиса и внешних зависимостей
+type HealthResponse struct {
+	Status    string                     `json:"status" example:"ok"`   // ok, degraded или down
+	Database  string                     `json:"database" example:"ok"` // Состояние базы данных
+	Providers []enrichment.BreakerStatus `json:"providers"`             // Circuit breaker провайдеров
+}
+
+// @Summary Состояние сервиса
+// @Description Проверяет базу данных и возвращает состояние circuit breaker провайдеров обогащения.
+// @Description Открытый circuit breaker переводит статус в degraded, недоступная база — в down (503).
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /health [get]
+func Health(people repository.PersonRepository, enrichers *enrichment.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response := HealthResponse{Status: "ok", Database: "ok", Providers: enrichers.Breakers()}
+		for _, p := range response.Providers {
+			if p.State != enrichment.BreakerClosed {
+				response.Status = "degraded"
+			}
+		}
+		if err := people.Ping(c.Request.Context()); err != nil {
+			logrus.Errorf("База данных недоступна: %v", err)
+			response.Status = "down"
+			response.Database = "down"
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "person-api/enrichment"
+    "person-api/repository"
+)
+
+// TestHealth тестирует health-эндпоинт с состоянием circuit breaker
+func TestHealth(t *testing.T) {
+    _, db := setupRouter()
+    enrichers := enrichment.NewRegistry(
+        enrichment.NewGenderize(http.DefaultClient, enrichment.DefaultGenderizeConfig),
+        fakeEnricher{},
+    )
+    r := gin.Default()
+    r.GET("/health", Health(repository.NewGormPersonRepository(db), enrichers))
+    req, _ := http.NewRequest("GET", "/health", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response HealthResponse
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "ok", response.Status)
+    assert.Equal(t, "ok", response.Database)
+    if assert.Len(t, response.Providers, 1) {
+        assert.Equal(t, "genderize", response.Providers[0].Provider)
+        assert.Equal(t, enrichment.BreakerClosed, response.Providers[0].State)
+    }
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"person-api/repository"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ActorHeader заголовок с автором изменений (попадает в историю версий)
+const ActorHeader = "X-Actor"
+
+// Actor сохраняет в контексте запроса автора изменений из заголовка X-Actor
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := strings.TrimSpace(c.GetHeader(ActorHeader)); actor != "" {
+			c.Request = c.Request.WithContext(repository.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
+
+// @Summary История изменений человека
+// @Description Возвращает версии человека по возрастанию номера: операцию, автора (X-Actor),
+// @Description снимок после изменения и изменённые поля со значениями до и после.
+// @Tags people
+// @Produce json
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Success 200 {array} models.PersonVersion
+// @Header 200 {string} Cache-Control "Директивы кэширования (CACHE_CONTROL_HISTORY)"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/{id}/history [get]
+func GetPersonHistory(people repository.PersonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		versions, err := people.History(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err, "Не удалось получить историю")
+			return
+		}
+		if len(versions) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Не найден"})
+			return
+		}
+		setCacheControl(c)
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+// @Summary Вернуть человека к версии
+// @Description Возвращает имя, фамилию, отчество, возраст, пол и национальность к состоянию указанной версии.
+// @Description Возврат записывается в историю новой версией, поэтому его тоже можно отменить.
+// @Tags people
+// @Produce json
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param version path int true "Номер версии"
+// @Param X-Actor header string false "Кто выполняет возврат"
+// @Success 200 {object} models.Person
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/{id}/revert/{version} [post]
+func RevertPerson(people repository.PersonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		number, ok := positiveParam(c, "version")
+		if !ok {
+			return
+		}
+		person, err := people.Revert(c.Request.Context(), id, number)
+		if err != nil {
+			respondError(c, err, "Не удалось вернуть версию")
+			return
+		}
+		logrus.Infof("ID %d возвращён к версии %d", id, number)
+		setPersonETag(c, person)
+		c.JSON(http.StatusOK, person)
+	}
+}
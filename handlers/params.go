@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"person-api/models"
+	"person-api/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// personID возвращает внутренний ID человека из параметра :id. Принимается публичный UUID
+// и, на переходный период, прежний числовой ID. Некорректный ID — 400, неизвестный UUID — 404.
+func personID(c *gin.Context, people repository.PersonRepository) (uint, bool) {
+	param := c.Param("id")
+	if publicID, ok := models.ParseUUID(param); ok {
+		id, err := people.FindID(c.Request.Context(), publicID)
+		if err != nil {
+			respondError(c, err, "Не удалось получить")
+			return 0, false
+		}
+		return id, true
+	}
+	id, err := strconv.ParseUint(param, 10, 0)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID: ожидается UUID или положительное число"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// positiveParam возвращает положительное число из параметра пути name; иначе отвечает 400
+func positiveParam(c *gin.Context, name string) (int, bool) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil || value <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр " + name + " должен быть положительным числом"})
+		return 0, false
+	}
+	return value, true
+}
+
+// queryInt возвращает целый параметр запроса name не меньше min (def — если параметра нет);
+// некорректное значение — 400
+func queryInt(c *gin.Context, name string, def, min int) (int, bool) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return def, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр " + name + " должен быть целым числом не меньше " + strconv.Itoa(min)})
+		return 0, false
+	}
+	return value, true
+}
+
+// respondError отвечает на ошибку хранилища: нет записи — 404, запись изменена другим
+// запросом — 412, остальные ошибки базы — 500 с сообщением message (подробности только в логе)
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Не найден"})
+	case errors.Is(err, repository.ErrVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Версия не найдена"})
+	case errors.Is(err, repository.ErrConflict):
+		logrus.Warnf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Запись изменена другим запросом"})
+	default:
+		logrus.Errorf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -1,49 +1,69 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"net/http"
+	"person-api/enrichment"
 	"person-api/models"
+	"person-api/repository"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 )
 
-// PersonCreate определяет структуру для создания человека
+// PersonCreate определяет структуру для создания человека.
+// Указанные клиентом возраст, пол и национальность не запрашиваются у провайдеров
+// и не перезаписываются при переобогащении.
 type PersonCreate struct {
-	Name       string `json:"name" binding:"required"`    // Имя (обязательное)
-	Surname    string `json:"surname" binding:"required"` // Фамилия (обязательная)
-	Patronymic string `json:"patronymic"`                 // Отчество (опционально)
+	Name        string `json:"name" binding:"required"`                                     // Имя (обязательное)
+	Surname     string `json:"surname" binding:"required"`                                  // Фамилия (обязательная)
+	Patronymic  string `json:"patronymic"`                                                  // Отчество (опционально)
+	Age         *int   `json:"age" binding:"omitempty,min=0,max=150" example:"42"`          // Возраст (опционально)
+	Gender      string `json:"gender" binding:"omitempty,oneof=male female" example:"male"` // Пол (опционально)
+	Nationality string `json:"nationality" binding:"omitempty,len=2,alpha" example:"RU"`    // Код страны (опционально)
 }
 
-// PersonUpdate определяет структуру для обновления человека
-type PersonUpdate struct {
-	Name        *string `json:"name"`        // Имя (опционально)
-	Surname     *string `json:"surname"`     // Фамилия (опционально)
-	Patronymic  *string `json:"patronymic"`  // Отчество (опционально)
-	Age         *int    `json:"age"`         // Возраст (опционально)
-	Gender      *string `json:"gender"`      // Пол (опционально)
-	Nationality *string `json:"nationality"` // Национальность (опционально)
-}
-
-// GenderizeResponse структура ответа от Genderize.io
-type GenderizeResponse struct {
-	Gender      string  `json:"gender"`      // Пол
-	Probability float64 `json:"probability"` // Вероятность
+// newPerson создаёт модель человека из входных данных, помечая указанные клиентом поля
+func newPerson(input PersonCreate) models.Person {
+	person := models.Person{
+		Name:       input.Name,
+		Surname:    input.Surname,
+		Patronymic: input.Patronymic,
+	}
+	if input.Age != nil {
+		person.Age = input.Age
+		person.AgeSource = models.SourceClient
+	}
+	if input.Gender != "" {
+		person.Gender = input.Gender
+		person.GenderSource = models.SourceClient
+	}
+	if input.Nationality != "" {
+		person.Nationality = strings.ToUpper(input.Nationality)
+		person.NationalitySource = models.SourceClient
+	}
+	return person
 }
 
-// NationalizeResponse структура ответа от Nationalize.io
-type NationalizeResponse struct {
-	Country []struct {
-		CountryID   string  `json:"country_id"`  // Код страны
-		Probability float64 `json:"probability"` // Вероятность
-	} `json:"country"`
+// PersonUpdate определяет структуру для обновления человека. Проверки те же, что при создании;
+// пустые пол и национальность очищают поле, и его снова можно обогатить.
+type PersonUpdate struct {
+	Name        *string `json:"name"`                                                                 // Имя (опционально)
+	Surname     *string `json:"surname"`                                                              // Фамилия (опционально)
+	Patronymic  *string `json:"patronymic"`                                                           // Отчество (опционально)
+	Age         *int    `json:"age" binding:"omitempty,min=0,max=150" example:"42"`                   // Возраст (опционально)
+	Gender      *string `json:"gender" binding:"omitempty,oneof=male female ''" example:"male"`       // Пол (опционально)
+	Nationality *string `json:"nationality" binding:"omitempty,max=0|len=2,max=0|alpha" example:"RU"` // Код страны (опционально)
 }
 
 // @Summary Создание нового человека
-// @Description Принимает имя, фамилию и отчество. Определяет пол и национальность с помощью внешних API.
+// @Description Принимает имя, фамилию и отчество. Определяет пол, национальность и возраст с помощью внешних API.
+// @Description Возраст, пол и национальность можно передать сразу: такие поля не обогащаются (источник client).
+// @Description В асинхронном режиме запись сохраняется сразу со статусом обогащения pending.
 // @Tags people
 // @Accept json
 // @Produce json
@@ -52,7 +72,7 @@ type NationalizeResponse struct {
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /people [post]
-func CreatePerson(db *gorm.DB) gin.HandlerFunc {
+func CreatePerson(people repository.PersonRepository, enrichers *enrichment.Registry, mode enrichment.Mode) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input PersonCreate
 		// Валидируем входные данные
@@ -62,41 +82,134 @@ func CreatePerson(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 		// Создаём модель человека
-		person := models.Person{
-			Name:       input.Name,
-			Surname:    input.Surname,
-			Patronymic: input.Patronymic,
-		}
-		// Запрашиваем пол через Genderize.io
-		if resp, err := http.Get("https://api.genderize.io?name=" + person.Name); err == nil {
-			defer resp.Body.Close()
-			var genderResp GenderizeResponse
-			if err := json.NewDecoder(resp.Body).Decode(&genderResp); err == nil && genderResp.Probability > 0.7 {
-				person.Gender = genderResp.Gender
-			}
-		}
-		// Запрашиваем национальность через Nationalize.io
-		if resp, err := http.Get("https://api.nationalize.io?name=" + person.Name); err == nil {
-			defer resp.Body.Close()
-			var natResp NationalizeResponse
-			if err := json.NewDecoder(resp.Body).Decode(&natResp); err == nil && len(natResp.Country) > 0 && natResp.Country[0].Probability > 0.3 {
-				person.Nationality = natResp.Country[0].CountryID
+		person := newPerson(input)
+		// Время постановки в очередь фонового обогащения (нулевое — без очереди)
+		var enqueueAt time.Time
+		if mode == enrichment.ModeAsync {
+			// Сохраняем сразу, обогащение выполнят фоновые воркеры
+			person.EnrichmentStatus = models.EnrichmentPending
+			enqueueAt = time.Now()
+		} else {
+			// Определяем пол, национальность и т.п. через внешние API.
+			// Ошибки провайдеров не мешают созданию записи.
+			person.EnrichmentStatus = models.EnrichmentDone
+			if err := enrichers.Enrich(c.Request.Context(), &person); err != nil {
+				logrus.Warnf("Обогащение выполнено частично: %v", err)
+				// Квота провайдера исчерпана — дообогатим в фоне после её сброса
+				if resetAt, ok := enrichment.QuotaResetAt(err); ok {
+					person.EnrichmentStatus = models.EnrichmentPending
+					enqueueAt = resetAt
+				}
 			}
 		}
 		logrus.Infof("Создание: %s %s", person.Name, person.Surname)
-		// Сохраняем в базе
-		if err := db.Create(&person).Error; err != nil {
+		// Сохраняем в базе вместе с задачей на обогащение
+		err := people.Transaction(c.Request.Context(), func(ctx context.Context) error {
+			if err := people.Create(ctx, &person); err != nil {
+				return err
+			}
+			if !enqueueAt.IsZero() {
+				return people.Enqueue(ctx, person.ID, enqueueAt)
+			}
+			return nil
+		})
+		if err != nil {
 			logrus.Errorf("Ошибка создания: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать"})
 			return
 		}
 		logrus.Infof("Создан ID: %d", person.ID)
+		// Происхождение данных отдаётся только через GET /people/:id?include=enrichment
+		person.Enrichments = nil
 		c.JSON(http.StatusOK, person)
 	}
 }
 
+// MaxBatchCreate максимум людей в одном запросе POST /people/batch
+const MaxBatchCreate = 100
+
+// @Summary Создание нескольких людей
+// @Description Принимает массив людей (не больше 100) и создаёт их одной транзакцией.
+// @Description Имена отправляются провайдерам пакетными запросами по 10 штук.
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param people body []PersonCreate true "Данные для создания"
+// @Success 200 {array} models.Person
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/batch [post]
+func CreatePeople(repo repository.PersonRepository, enrichers *enrichment.Registry, mode enrichment.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var inputs []PersonCreate
+		// Валидируем входные данные
+		if err := c.ShouldBindJSON(&inputs); err != nil {
+			logrus.Errorf("Ошибка ввода: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(inputs) == 0 || len(inputs) > MaxBatchCreate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ожидается от 1 до " + strconv.Itoa(MaxBatchCreate) + " человек"})
+			return
+		}
+		people := make([]models.Person, len(inputs))
+		batch := make([]*models.Person, len(inputs))
+		for i, input := range inputs {
+			people[i] = newPerson(input)
+			batch[i] = &people[i]
+		}
+		var enqueueAt time.Time
+		status := models.EnrichmentDone
+		if mode == enrichment.ModeAsync {
+			status = models.EnrichmentPending
+			enqueueAt = time.Now()
+		} else if err := enrichers.EnrichBatch(c.Request.Context(), batch); err != nil {
+			logrus.Warnf("Обогащение выполнено частично: %v", err)
+			if resetAt, ok := enrichment.QuotaResetAt(err); ok {
+				status = models.EnrichmentPending
+				enqueueAt = resetAt
+			}
+		}
+		for i := range people {
+			people[i].EnrichmentStatus = status
+		}
+		logrus.Infof("Создание: %d человек", len(people))
+		err := repo.Transaction(c.Request.Context(), func(ctx context.Context) error {
+			for _, person := range batch {
+				if err := repo.Create(ctx, person); err != nil {
+					return err
+				}
+				if enqueueAt.IsZero() {
+					continue
+				}
+				if err := repo.Enqueue(ctx, person.ID, enqueueAt); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logrus.Errorf("Ошибка создания: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать"})
+			return
+		}
+		for i := range people {
+			people[i].Enrichments = nil
+		}
+		c.JSON(http.StatusOK, people)
+	}
+}
+
+// Размер страницы GET /people: по умолчанию и максимальный
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
 // @Summary Получить список людей
-// @Description Возвращает список людей с фильтрацией и пагинацией
+// @Description Возвращает список людей с фильтрацией и пагинацией. ETag и Last-Modified вычисляются
+// @Description по фильтру и времени последнего изменения подходящих людей; с If-None-Match или
+// @Description If-Modified-Since неизменившийся список не передаётся повторно (304).
 // @Tags people
 // @Produce json
 // @Param name query string false "Фильтр по имени"
@@ -104,38 +217,70 @@ func CreatePerson(db *gorm.DB) gin.HandlerFunc {
 // @Param age query int false "Фильтр по возрасту"
 // @Param gender query string false "Фильтр по полу"
 // @Param nationality query string false "Фильтр по национальности"
-// @Param skip query int false "Смещение (пагинация)"
-// @Param limit query int false "Ограничение (пагинация)"
+// @Param skip query int false "Смещение (пагинация)" minimum(0) default(0)
+// @Param limit query int false "Ограничение (пагинация)" minimum(1) maximum(100) default(10)
+// @Param include_deleted query bool false "Включая удалённых (только с токеном администратора)"
+// @Param X-Admin-Token header string false "Токен администратора (для include_deleted)"
+// @Param If-None-Match header string false "ETag сохранённой копии"
+// @Param If-Modified-Since header string false "Last-Modified сохранённой копии"
 // @Success 200 {array} models.Person
+// @Success 304 "Копия клиента актуальна"
+// @Header 200,304 {integer} X-Total-Count "Количество записей по фильтру без учёта пагинации"
+// @Header 200,304 {string} ETag "Версия списка"
+// @Header 200,304 {string} Last-Modified "Время последнего изменения подходящих людей"
+// @Header 200,304 {string} Cache-Control "Директивы кэширования (CACHE_CONTROL_PEOPLE)"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /people [get]
-func GetPeople(db *gorm.DB) gin.HandlerFunc {
+func GetPeople(repo repository.PersonRepository, adminToken string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var people []models.Person
-		query := db
-		// Фильтры по параметрам запроса
-		if name := c.Query("name"); name != "" {
-			query = query.Where("name ILIKE ?", "%"+name+"%")
+		filter, ok := personFilter(c)
+		if !ok {
+			return
+		}
+		// Удалённых видят только администраторы
+		if c.Query("include_deleted") == "true" {
+			if !isAdmin(c, adminToken) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется токен администратора"})
+				return
+			}
+			filter.IncludeDeleted = true
+		}
+		// Пагинация: Limit 0 в хранилище означает «без ограничения», поэтому здесь он не допускается
+		if filter.Offset, ok = queryInt(c, "skip", 0, 0); !ok {
+			return
+		}
+		if filter.Limit, ok = queryInt(c, "limit", DefaultPageSize, 1); !ok {
+			return
 		}
-		if surname := c.Query("surname"); surname != "" {
-			query = query.Where("surname ILIKE ?", "%"+surname+"%")
+		if filter.Limit > MaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр limit не должен превышать " + strconv.Itoa(MaxPageSize)})
+			return
 		}
-		if age := c.Query("age"); age != "" {
-			ageInt, _ := strconv.Atoi(age)
-			query = query.Where("age = ?", ageInt)
+		// Общее количество подходящих записей для пагинации
+		total, err := repo.Count(c.Request.Context(), filter)
+		if err != nil {
+			logrus.Errorf("Ошибка подсчёта: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+			return
 		}
-		if gender := c.Query("gender"); gender != "" {
-			query = query.Where("gender = ?", gender)
+		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		// Время последнего изменения считаем и по удалённым: удаление тоже меняет список
+		modifiedFilter := filter
+		modifiedFilter.IncludeDeleted = true
+		modified, err := repo.LastModified(c.Request.Context(), modifiedFilter)
+		if err != nil {
+			logrus.Errorf("Ошибка получения времени изменения: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+			return
 		}
-		if nationality := c.Query("nationality"); nationality != "" {
-			query = query.Where("nationality = ?", nationality)
+		if notModified(c, listETag(c.Request.URL.Query(), total, modified), modified) {
+			return
 		}
-		// Пагинация
-		skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
-		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-		query = query.Offset(skip).Limit(limit)
 		// Выполняем запрос
-		if err := query.Find(&people).Error; err != nil {
+		people, err := repo.List(c.Request.Context(), filter)
+		if err != nil {
 			logrus.Errorf("Ошибка получения: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
 			return
@@ -145,22 +290,110 @@ func GetPeople(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// clientSource возвращает источник client для заданного значения;
+// очищенное клиентом поле снова можно обогатить
+func clientSource(value string) string {
+	if value == "" {
+		return ""
+	}
+	return models.SourceClient
+}
+
+// resetEnriched очищает пол, национальность и возраст, не указанные клиентом, и сообщает,
+// осталось ли что обогащать
+func resetEnriched(person *models.Person) bool {
+	reset := false
+	if person.GenderSource != models.SourceClient {
+		person.Gender, person.GenderSource = "", ""
+		reset = true
+	}
+	if person.NationalitySource != models.SourceClient {
+		person.Nationality, person.NationalitySource = "", ""
+		reset = true
+	}
+	if person.AgeSource != models.SourceClient {
+		person.Age, person.AgeSource = nil, ""
+		reset = true
+	}
+	return reset
+}
+
+// personFilter строит фильтр из параметров запроса GET /people (без пагинации);
+// при некорректном возрасте отвечает 400
+func personFilter(c *gin.Context) (repository.PersonFilter, bool) {
+	filter := repository.PersonFilter{
+		Name:        c.Query("name"),
+		Surname:     c.Query("surname"),
+		Gender:      c.Query("gender"),
+		Nationality: c.Query("nationality"),
+	}
+	if _, ok := c.GetQuery("age"); ok {
+		age, ok := queryInt(c, "age", 0, 0)
+		if !ok {
+			return filter, false
+		}
+		filter.Age = &age
+	}
+	return filter, true
+}
+
 // @Summary Получить человека по ID
-// @Description Возвращает информацию о человеке по его ID
+// @Description Возвращает информацию о человеке по его ID. С include=enrichment добавляет ответы провайдеров
+// @Description с вероятностью, размером выборки и временем запроса. С as_of возвращает состояние на указанный момент
+// @Description по истории изменений.
 // @Tags people
 // @Produce json
-// @Param id path int true "ID человека"
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param include query string false "Дополнительные данные: enrichment — происхождение обогащённых полей"
+// @Param as_of query string false "Момент времени в формате RFC 3339, например 2025-01-01T12:00:00Z"
+// @Param If-None-Match header string false "ETag сохранённой копии"
+// @Param If-Modified-Since header string false "Last-Modified сохранённой копии"
 // @Success 200 {object} models.Person
+// @Success 304 "Копия клиента актуальна"
+// @Header 200,304 {string} ETag "Версия записи, с include=enrichment — и записей о происхождении (кроме ответов с as_of)"
+// @Header 200,304 {string} Last-Modified "Время последнего изменения (кроме ответов с as_of и include=enrichment)"
+// @Header 200,304 {string} Cache-Control "Директивы кэширования (CACHE_CONTROL_PERSON)"
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /people/{id} [get]
-func GetPerson(db *gorm.DB) gin.HandlerFunc {
+func GetPerson(people repository.PersonRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, _ := strconv.Atoi(c.Param("id"))
-		var person models.Person
-		// Ищем запись
-		if err := db.First(&person, id).Error; err != nil {
-			logrus.Errorf("Не найден ID=%d: %v", id, err)
-			c.JSON(http.StatusNotFound, gin.H{"error": "Не найден"})
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		// Ищем запись: текущую или на момент as_of
+		var person *models.Person
+		var err error
+		if asOf := c.Query("as_of"); asOf != "" {
+			at, parseErr := time.Parse(time.RFC3339, asOf)
+			if parseErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "as_of должен быть временем в формате RFC 3339"})
+				return
+			}
+			person, err = people.AsOf(c.Request.Context(), id, at)
+		} else {
+			person, err = people.Get(c.Request.Context(), id)
+		}
+		if err != nil {
+			respondError(c, err, "Не удалось получить")
+			return
+		}
+		etag, modified := personETag(person), person.UpdatedAt
+		if c.Query("include") == "enrichment" {
+			if person.Enrichments, err = people.Enrichments(c.Request.Context(), person.ID); err != nil {
+				logrus.Errorf("Ошибка получения происхождения ID=%d: %v", id, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+				return
+			}
+			// Добавление записей о происхождении не меняет updated_at, поэтому только по ETag
+			etag, modified = enrichmentETag(person), time.Time{}
+		}
+		if c.Query("as_of") != "" {
+			setCacheControl(c)
+		} else if notModified(c, etag, modified) {
+			// У клиента актуальная копия: 304 без тела
 			return
 		}
 		logrus.Infof("Получен ID: %d", id)
@@ -170,24 +403,38 @@ func GetPerson(db *gorm.DB) gin.HandlerFunc {
 
 // @Summary Обновить данные человека
 // @Description Обновляет существующего человека по ID. Принимает только те поля, которые нужно изменить.
+// @Description С заголовком If-Match (ETag из GET /people/{id}) изменение применяется, только если запись
+// @Description не менялась; иначе 412. Параллельное изменение между чтением и записью тоже даёт 412.
+// @Description Смена имени сбрасывает пол, национальность и возраст, не указанные клиентом,
+// @Description и ставит человека в очередь фонового обогащения (статус pending).
 // @Tags people
 // @Accept json
 // @Produce json
-// @Param id path int true "ID человека"
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param If-Match header string false "ETag записи"
 // @Param person body PersonUpdate true "Обновляемые данные"
 // @Success 200 {object} models.Person
+// @Header 200 {string} ETag "Новая версия записи"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /people/{id} [put]
-func UpdatePerson(db *gorm.DB) gin.HandlerFunc {
+func UpdatePerson(people repository.PersonRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, _ := strconv.Atoi(c.Param("id"))
-		var person models.Person
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
 		// Ищем запись
-		if err := db.First(&person, id).Error; err != nil {
-			logrus.Errorf("Не найден ID=%d: %v", id, err)
-			c.JSON(http.StatusNotFound, gin.H{"error": "Не найден"})
+		person, err := people.Get(c.Request.Context(), id)
+		if err != nil {
+			respondError(c, err, "Не удалось получить")
+			return
+		}
+		if !ifMatch(c, person) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Запись изменена: ETag не совпадает с If-Match"})
 			return
 		}
 		var input PersonUpdate
@@ -197,6 +444,8 @@ func UpdatePerson(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		// Пол, национальность и возраст определялись по прежнему имени
+		renamed := input.Name != nil && *input.Name != person.Name
 		// Обновляем поля
 		if input.Name != nil {
 			person.Name = *input.Name
@@ -207,44 +456,123 @@ func UpdatePerson(db *gorm.DB) gin.HandlerFunc {
 		if input.Patronymic != nil {
 			person.Patronymic = *input.Patronymic
 		}
+		// Заданные вручную возраст, пол и национальность больше не перезаписываются обогащением
 		if input.Age != nil {
 			person.Age = input.Age
+			person.AgeSource = models.SourceClient
 		}
 		if input.Gender != nil {
 			person.Gender = *input.Gender
+			person.GenderSource = clientSource(*input.Gender)
 		}
 		if input.Nationality != nil {
-			person.Nationality = *input.Nationality
+			person.Nationality = strings.ToUpper(*input.Nationality)
+			person.NationalitySource = clientSource(*input.Nationality)
+		}
+		// После смены имени обогащённые поля сбрасываются и определяются заново в фоне
+		reenrich := renamed && resetEnriched(person)
+		if reenrich {
+			person.EnrichmentStatus = models.EnrichmentPending
 		}
-		// Сохраняем изменения
-		if err := db.Save(&person).Error; err != nil {
-			logrus.Errorf("Ошибка обновления ID=%d: %v", id, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить"})
+		// Сохраняем изменения вместе с задачей на обогащение
+		err = people.Transaction(c.Request.Context(), func(ctx context.Context) error {
+			if err := people.Update(ctx, person); err != nil {
+				return err
+			}
+			if reenrich {
+				return people.Enqueue(ctx, person.ID, time.Now())
+			}
+			return nil
+		})
+		if err != nil {
+			respondError(c, err, "Не удалось обновить")
 			return
 		}
 		logrus.Infof("Обновлён ID: %d", id)
+		setPersonETag(c, person)
 		c.JSON(http.StatusOK, person)
 	}
 }
 
 // @Summary Удалить человека
-// @Description Удаляет человека по ID
+// @Description Помечает человека удалённым. Администратор может восстановить его через POST /people/{id}/restore,
+// @Description пока не истёк срок хранения удалённых (PEOPLE_RETENTION).
+// @Description С заголовком If-Match удаляет, только если запись не менялась; иначе 412.
 // @Tags people
 // @Produce json
-// @Param id path int true "ID человека"
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param If-Match header string false "ETag записи"
 // @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /people/{id} [delete]
-func DeletePerson(db *gorm.DB) gin.HandlerFunc {
+func DeletePerson(people repository.PersonRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id, _ := strconv.Atoi(c.Param("id"))
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		// С If-Match удаляем только ту версию, которую видел клиент
+		version := 0
+		if c.GetHeader("If-Match") != "" {
+			person, err := people.Get(c.Request.Context(), id)
+			if err != nil {
+				respondError(c, err, "Не удалось получить")
+				return
+			}
+			if !ifMatch(c, person) {
+				c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Запись изменена: ETag не совпадает с If-Match"})
+				return
+			}
+			version = person.Version
+		}
 		// Удаляем запись
-		if err := db.Delete(&models.Person{}, id).Error; err != nil {
-			logrus.Errorf("Ошибка удаления ID=%d: %v", id, err)
-			c.JSON(http.StatusNotFound, gin.H{"error": "Не найден"})
+		if err := people.Delete(c.Request.Context(), id, version); err != nil {
+			respondError(c, err, "Не удалось удалить")
 			return
 		}
 		logrus.Infof("Удалён ID: %d", id)
 		c.JSON(http.StatusOK, gin.H{"message": "Удалён"})
 	}
 }
+
+// @Summary Восстановить удалённого человека
+// @Description Снимает отметку об удалении, если запись ещё не очищена по сроку хранения
+// @Tags people
+// @Produce json
+// @Param id path string true "UUID человека (на переходный период также числовой ID)"
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Success 200 {object} models.Person
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /people/{id}/restore [post]
+func RestorePerson(people repository.PersonRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := personID(c, people)
+		if !ok {
+			return
+		}
+		if err := people.Restore(c.Request.Context(), id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Удалённая запись не найдена"})
+				return
+			}
+			respondError(c, err, "Не удалось восстановить")
+			return
+		}
+		person, err := people.Get(c.Request.Context(), id)
+		if err != nil {
+			logrus.Errorf("Ошибка чтения ID=%d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить"})
+			return
+		}
+		logrus.Infof("Восстановлен ID: %d", id)
+		setPersonETag(c, person)
+		c.JSON(http.StatusOK, person)
+	}
+}
@@ -2,30 +2,90 @@ package handlers
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
+    "time"
     "github.com/gin-gonic/gin"
     "github.com/stretchr/testify/assert"
     "gorm.io/driver/sqlite"
     "gorm.io/gorm"
+    "person-api/enrichment"
     "person-api/models"
+    "person-api/repository"
 )
 
+// fakeEnricher подменяет внешние API в тестах
+type fakeEnricher struct {
+    gender      string
+    nationality string
+}
+
+func (f fakeEnricher) Name() string {
+    return "fake"
+}
+
+func (f fakeEnricher) Enrich(ctx context.Context, person *models.Person) error {
+    person.Gender = f.gender
+    person.Nationality = f.nationality
+    person.Enrichments = append(person.Enrichments, models.PersonEnrichment{
+        Provider: "fake", Field: "gender", Value: f.gender, Accepted: true,
+    })
+    return nil
+}
+
+// quotaEnricher имитирует провайдера с исчерпанной квотой
+type quotaEnricher struct {
+    resetAt time.Time
+}
+
+func (q quotaEnricher) Name() string {
+    return "quota"
+}
+
+func (q quotaEnricher) Enrich(ctx context.Context, person *models.Person) error {
+    return &enrichment.QuotaExhaustedError{Provider: q.Name(), ResetAt: q.resetAt}
+}
+
+// testAdminToken токен администратора в тестах
+const testAdminToken = "secret"
+
 // setupRouter создаёт тестовый роутер и базу данных
 func setupRouter() (*gin.Engine, *gorm.DB) {
+    return setupRouterWithMode(enrichment.ModeSync)
+}
+
+// setupRouterWithMode создаёт тестовый роутер с заданным режимом обогащения
+func setupRouterWithMode(mode enrichment.Mode) (*gin.Engine, *gorm.DB) {
     gin.SetMode(gin.TestMode)
     // Используем SQLite в памяти для тестов
     db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-    db.AutoMigrate(&models.Person{})
+    // Каждое соединение к :memory: — отдельная база, поэтому держим одно соединение
+    sqlDB, _ := db.DB()
+    sqlDB.SetMaxOpenConns(1)
+    db.AutoMigrate(&models.Person{}, &models.EnrichmentJob{}, &models.PersonEnrichment{}, &models.PersonVersion{})
     r := gin.Default()
+    r.Use(Actor())
     // Регистрируем маршруты
-    r.POST("/people", CreatePerson(db))
-    r.GET("/people", GetPeople(db))
-    r.GET("/people/:id", GetPerson(db))
-    r.PUT("/people/:id", UpdatePerson(db))
-    r.DELETE("/people/:id", DeletePerson(db))
+    enrichers := enrichment.NewRegistry(fakeEnricher{gender: "male", nationality: "RU"})
+    people := repository.NewGormPersonRepository(db)
+    r.POST("/people", CreatePerson(people, enrichers, mode))
+    r.POST("/people/batch", CreatePeople(people, enrichers, mode))
+    r.GET("/people", CacheControl(DefaultCacheControl.People), GetPeople(people, testAdminToken))
+    r.GET("/people/:id", CacheControl(DefaultCacheControl.Person), GetPerson(people))
+    r.GET("/people/:id/enrichment", GetEnrichmentStatus(people))
+    jobs := enrichment.NewJobs()
+    r.POST("/people/:id/enrich", EnrichPerson(people, enrichers))
+    r.POST("/people/enrich", EnrichPeople(people, enrichers, jobs))
+    r.GET("/enrichment/jobs/:id", GetEnrichmentJob(jobs))
+    r.PUT("/people/:id", RequireIfMatch(false), UpdatePerson(people))
+    r.DELETE("/people/:id", RequireIfMatch(false), DeletePerson(people))
+    r.POST("/people/:id/restore", AdminAuth(testAdminToken), RestorePerson(people))
+    r.GET("/people/:id/history", GetPersonHistory(people))
+    r.POST("/people/:id/revert/:version", RevertPerson(people))
     return r, db
 }
 
@@ -42,6 +102,224 @@ func TestCreatePerson(t *testing.T) {
     json.Unmarshal(w.Body.Bytes(), &person)
     assert.Equal(t, "Дмитрий", person.Name)
     assert.Equal(t, "Ушаков", person.Surname)
+    assert.Equal(t, "male", person.Gender)
+    assert.Equal(t, "RU", person.Nationality)
+}
+
+// TestCreatePersonClientValues тестирует приоритет переданных клиентом значений над обогащением
+func TestCreatePersonClientValues(t *testing.T) {
+    r, db := setupRouter()
+    payload := `{"name":"Дмитрий","surname":"Ушаков","gender":"female","nationality":"ua","age":30}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, "female", person.Gender)
+    assert.Equal(t, "UA", person.Nationality)
+    assert.Equal(t, 30, *person.Age)
+    assert.Equal(t, models.SourceClient, person.GenderSource)
+    assert.Equal(t, models.SourceClient, person.AgeSource)
+
+    // Переобогащение не перезаписывает значения клиента
+    req, _ = http.NewRequest("POST", "/people/1/enrich", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var saved models.Person
+    db.First(&saved, "public_id = ?", person.PublicID)
+    assert.Equal(t, "female", saved.Gender)
+    assert.Equal(t, "UA", saved.Nationality)
+
+    // Некорректные значения отклоняются
+    for _, payload := range []string{
+        `{"name":"Дмитрий","surname":"Ушаков","gender":"unknown"}`,
+        `{"name":"Дмитрий","surname":"Ушаков","age":-1}`,
+        `{"name":"Дмитрий","surname":"Ушаков","nationality":"Russia"}`,
+    } {
+        req, _ = http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+        req.Header.Set("Content-Type", "application/json")
+        w = httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+    }
+}
+
+// TestUpdatePersonMarksClientSource тестирует пометку полей, изменённых через PUT
+func TestUpdatePersonMarksClientSource(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{Name: "Дмитрий", Surname: "Ушаков", Gender: "male", GenderSource: models.SourceProvider})
+    req, _ := http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"gender":"female"}`)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var saved models.Person
+    db.First(&saved, 1)
+    assert.Equal(t, "female", saved.Gender)
+    assert.Equal(t, models.SourceClient, saved.GenderSource)
+}
+
+// TestUpdatePersonValidation тестирует проверку пола, национальности и возраста при обновлении
+func TestUpdatePersonValidation(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{Name: "Дмитрий", Surname: "Ушаков", Gender: "male", GenderSource: models.SourceProvider})
+    for _, payload := range []string{`{"gender":"xyz"}`, `{"nationality":"rus"}`, `{"nationality":"r1"}`, `{"age":200}`, `{"age":-1}`} {
+        req, _ := http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(payload)))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+    }
+    req, _ := http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"nationality":"ru","gender":""}`)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var saved models.Person
+    db.First(&saved, 1)
+    assert.Equal(t, "RU", saved.Nationality)
+    assert.Equal(t, models.SourceClient, saved.NationalitySource)
+    // Очищенный пол снова доступен обогащению
+    assert.Empty(t, saved.Gender)
+    assert.Empty(t, saved.GenderSource)
+}
+
+// TestUpdatePersonNameReenriches тестирует сброс обогащённых полей и постановку в очередь при смене имени
+func TestUpdatePersonNameReenriches(t *testing.T) {
+    r, db := setupRouter()
+    age := 42
+    db.Create(&models.Person{Name: "Дмитрий", Surname: "Ушаков", Gender: "male", GenderSource: models.SourceProvider,
+        Nationality: "RU", NationalitySource: models.SourceClient, Age: &age, AgeSource: models.SourceProvider,
+        EnrichmentStatus: models.EnrichmentDone})
+    // Второй запрос имени не меняет и новую задачу не ставит
+    for _, payload := range []string{`{"name":"Анна"}`, `{"name":"Анна","surname":"Ушакова"}`} {
+        req, _ := http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(payload)))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code)
+    }
+    var saved models.Person
+    db.First(&saved, 1)
+    assert.Empty(t, saved.Gender)
+    assert.Empty(t, saved.GenderSource)
+    assert.Nil(t, saved.Age)
+    // Указанная клиентом национальность сохраняется
+    assert.Equal(t, "RU", saved.Nationality)
+    assert.Equal(t, models.EnrichmentPending, saved.EnrichmentStatus)
+    var jobs []models.EnrichmentJob
+    db.Where("person_id = ?", 1).Find(&jobs)
+    if assert.Len(t, jobs, 1) {
+        assert.Equal(t, models.EnrichmentPending, jobs[0].Status)
+    }
+}
+
+// TestCreatePeople тестирует создание нескольких людей одним запросом
+func TestCreatePeople(t *testing.T) {
+    r, _ := setupRouter()
+    payload := `[{"name":"Дмитрий","surname":"Ушаков"},{"name":"Иван","surname":"Петров"}]`
+    req, _ := http.NewRequest("POST", "/people/batch", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var people []models.Person
+    json.Unmarshal(w.Body.Bytes(), &people)
+    if assert.Len(t, people, 2) {
+        assert.NotEmpty(t, people[0].PublicID)
+        assert.Equal(t, "Иван", people[1].Name)
+        assert.Equal(t, "male", people[1].Gender)
+        assert.Equal(t, models.EnrichmentDone, people[1].EnrichmentStatus)
+    }
+
+    // Пустой массив и запись без фамилии отклоняются
+    for _, payload := range []string{`[]`, `[{"name":"Иван"}]`} {
+        req, _ = http.NewRequest("POST", "/people/batch", bytes.NewBuffer([]byte(payload)))
+        req.Header.Set("Content-Type", "application/json")
+        w = httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+    }
+}
+
+// TestGetPersonIncludeEnrichment тестирует выдачу происхождения обогащённых данных
+func TestGetPersonIncludeEnrichment(t *testing.T) {
+    r, _ := setupRouter()
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Без include происхождение не возвращается
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.NotContains(t, w.Body.String(), "enrichment\":[")
+    req, _ = http.NewRequest("GET", "/people/1?include=enrichment", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    if assert.Len(t, person.Enrichments, 1) {
+        assert.Equal(t, "fake", person.Enrichments[0].Provider)
+        assert.Equal(t, "male", person.Enrichments[0].Value)
+    }
+}
+
+// TestCreatePersonAsync тестирует создание человека с фоновым обогащением
+func TestCreatePersonAsync(t *testing.T) {
+    r, db := setupRouterWithMode(enrichment.ModeAsync)
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, models.EnrichmentPending, person.EnrichmentStatus)
+    assert.Empty(t, person.Gender)
+    // Задача поставлена в очередь
+    var jobs int64
+    db.Model(&models.EnrichmentJob{}).Joins("JOIN people ON people.id = enrichment_jobs.person_id").
+        Where("people.public_id = ?", person.PublicID).Count(&jobs)
+    assert.Equal(t, int64(1), jobs)
+    // Статус доступен через отдельный эндпоинт
+    req, _ = http.NewRequest("GET", "/people/1/enrichment", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var status models.EnrichmentStatus
+    json.Unmarshal(w.Body.Bytes(), &status)
+    assert.Equal(t, models.EnrichmentPending, status.Status)
+    assert.Equal(t, 0, status.Attempts)
+}
+
+// TestCreatePersonQuotaExhausted тестирует отложенное обогащение при исчерпанной квоте
+func TestCreatePersonQuotaExhausted(t *testing.T) {
+    _, db := setupRouter()
+    resetAt := time.Now().Add(time.Hour)
+    enrichers := enrichment.NewRegistry(quotaEnricher{resetAt: resetAt})
+    r := gin.Default()
+    r.POST("/people", CreatePerson(repository.NewGormPersonRepository(db), enrichers, enrichment.ModeSync))
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, models.EnrichmentPending, person.EnrichmentStatus)
+    var job models.EnrichmentJob
+    db.Joins("JOIN people ON people.id = enrichment_jobs.person_id").
+        Where("people.public_id = ?", person.PublicID).First(&job)
+    assert.WithinDuration(t, resetAt, job.RunAfter, time.Second)
 }
 
 // TestGetPeople тестирует получение списка людей
@@ -56,6 +334,28 @@ func TestGetPeople(t *testing.T) {
     json.Unmarshal(w.Body.Bytes(), &people)
     assert.Len(t, people, 1)
     assert.Equal(t, "Дмитрий", people[0].Name)
+    assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+}
+
+// TestGetPeopleQueryParams тестирует отказ на некорректные параметры пагинации и фильтра
+func TestGetPeopleQueryParams(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{Name: "Дмитрий", Surname: "Ушаков"})
+    for _, query := range []string{"limit=0", "limit=abc", "limit=-1", "limit=101", "skip=-1", "skip=abc", "age=abc", "age=-5"} {
+        req, _ := http.NewRequest("GET", "/people?"+query, nil)
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code, query)
+    }
+    req, _ := http.NewRequest("POST", "/people/enrich?limit=0", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    // Максимальный размер страницы допустим
+    req, _ = http.NewRequest("GET", "/people?limit=100", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
 }
 
 // TestGetPerson тестирует получение человека по ID
@@ -98,3 +398,441 @@ func TestDeletePerson(t *testing.T) {
     json.Unmarshal(w.Body.Bytes(), &response)
     assert.Equal(t, "Удалён", response["message"])
 }
+
+// TestPersonParams тестирует коды ответа: некорректный ID — 400, нет записи — 404, ошибка базы — 500
+func TestPersonParams(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{ID: 1, Name: "Дмитрий", Surname: "Ушаков"})
+    cases := []struct {
+        method string
+        path   string
+        code   int
+    }{
+        {"GET", "/people/abc", http.StatusBadRequest},
+        {"GET", "/people/0", http.StatusBadRequest},
+        {"GET", "/people/-1", http.StatusBadRequest},
+        {"GET", "/people/999", http.StatusNotFound},
+        {"GET", "/people/01912d68-783e-7a03-8f4e-2c5b9a3d7e10", http.StatusNotFound},
+        {"DELETE", "/people/abc", http.StatusBadRequest},
+        {"DELETE", "/people/999", http.StatusNotFound},
+        {"POST", "/people/1/revert/abc", http.StatusBadRequest},
+        {"POST", "/people/1/revert/0", http.StatusBadRequest},
+        {"POST", "/people/999/revert/1", http.StatusNotFound},
+        {"DELETE", "/people/1", http.StatusOK},
+        {"DELETE", "/people/1", http.StatusNotFound},
+    }
+    for _, tc := range cases {
+        req, _ := http.NewRequest(tc.method, tc.path, nil)
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, tc.code, w.Code, "%s %s", tc.method, tc.path)
+    }
+    // Ошибка базы — не 404
+    sqlDB, _ := db.DB()
+    sqlDB.Close()
+    req, _ := http.NewRequest("GET", "/people/1", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusInternalServerError, w.Code)
+    var response map[string]string
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "Не удалось получить", response["error"])
+}
+
+// TestDeletePersonRestore тестирует мягкое удаление, список с удалёнными и восстановление
+func TestDeletePersonRestore(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{ID: 1, Name: "Дмитрий", Surname: "Ушаков"})
+    req, _ := http.NewRequest("DELETE", "/people/1", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Удалённый не виден, но запись осталась в базе
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    var count int64
+    db.Unscoped().Model(&models.Person{}).Count(&count)
+    assert.Equal(t, int64(1), count)
+    // include_deleted только для администратора
+    req, _ = http.NewRequest("GET", "/people?include_deleted=true", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+    req.Header.Set(AdminTokenHeader, testAdminToken)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var people []models.Person
+    json.Unmarshal(w.Body.Bytes(), &people)
+    assert.Len(t, people, 1)
+    assert.True(t, people[0].DeletedAt.Valid)
+    // Восстановление
+    req, _ = http.NewRequest("POST", "/people/1/restore", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusUnauthorized, w.Code)
+    req.Header.Set(AdminTokenHeader, testAdminToken)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, "Дмитрий", person.Name)
+    assert.False(t, person.DeletedAt.Valid)
+    assert.NotContains(t, w.Body.String(), "deleted_at")
+    // Повторное восстановление — запись уже не удалена
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestUpdatePersonIfMatch тестирует ETag и отказ в изменении устаревшей версии
+func TestUpdatePersonIfMatch(t *testing.T) {
+    r, _ := setupRouter()
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    etag := w.Header().Get("ETag")
+    assert.Equal(t, `"1"`, etag)
+    // Изменение с актуальным ETag
+    req, _ = http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"name":"Иван"}`)))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("If-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, 2, person.Version)
+    // Повтор со старым ETag — запись уже изменена
+    req, _ = http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"surname":"Петров"}`)))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("If-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+    req, _ = http.NewRequest("DELETE", "/people/1", nil)
+    req.Header.Set("If-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+    req.Header.Set("If-Match", `"5", "2"`)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestRequireIfMatch тестирует отказ в изменении без If-Match, когда он обязателен
+func TestRequireIfMatch(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    r.DELETE("/people/:id", RequireIfMatch(true), func(c *gin.Context) {
+        c.Status(http.StatusOK)
+    })
+    req, _ := http.NewRequest("DELETE", "/people/1", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+    req.Header.Set("If-Match", "*")
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestConditionalGet тестирует ответы 304 по ETag и Last-Modified и заголовок Cache-Control
+func TestConditionalGet(t *testing.T) {
+    r, _ := setupRouter()
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Человек
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, DefaultCacheControl.Person, w.Header().Get("Cache-Control"))
+    etag := w.Header().Get("ETag")
+    lastModified := w.Header().Get("Last-Modified")
+    assert.NotEmpty(t, lastModified)
+    req.Header.Set("If-None-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotModified, w.Code)
+    assert.Empty(t, w.Body.String())
+    assert.Equal(t, etag, w.Header().Get("ETag"))
+    req.Header.Del("If-None-Match")
+    req.Header.Set("If-Modified-Since", lastModified)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotModified, w.Code)
+    // Список
+    req, _ = http.NewRequest("GET", "/people?gender=male", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    listETag := w.Header().Get("ETag")
+    assert.NotEmpty(t, listETag)
+    req.Header.Set("If-None-Match", listETag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotModified, w.Code)
+    assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+    // Другой фильтр — другой ETag
+    req, _ = http.NewRequest("GET", "/people?gender=male&nationality=RU", nil)
+    req.Header.Set("If-None-Match", listETag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // После изменения копии устарели
+    time.Sleep(5 * time.Millisecond)
+    req, _ = http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"patronymic":"Васильевич"}`)))
+    req.Header.Set("Content-Type", "application/json")
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    req.Header.Set("If-None-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("GET", "/people?gender=male", nil)
+    req.Header.Set("If-None-Match", listETag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // Ошибки не кэшируются
+    req, _ = http.NewRequest("GET", "/people/2", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+// TestConditionalGetEnrichment тестирует, что новые записи о происхождении меняют ETag ответа с include=enrichment
+func TestConditionalGetEnrichment(t *testing.T) {
+    r, db := setupRouter()
+    db.Create(&models.Person{ID: 1, Name: "Дмитрий", Surname: "Ушаков", Gender: "male"})
+    db.Create(&models.PersonEnrichment{PersonID: 1, Provider: "genderize", Field: "gender", Value: "male", Accepted: true})
+    req, _ := http.NewRequest("GET", "/people/1?include=enrichment", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    etag := w.Header().Get("ETag")
+    assert.Empty(t, w.Header().Get("Last-Modified"))
+    // Тело без происхождения — другой вариант с другим ETag
+    req, _ = http.NewRequest("GET", "/people/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.NotEqual(t, etag, w.Header().Get("ETag"))
+    req, _ = http.NewRequest("GET", "/people/1?include=enrichment", nil)
+    req.Header.Set("If-None-Match", etag)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotModified, w.Code)
+    // Обогащение добавило запись, не изменив полей и версии
+    db.Create(&models.PersonEnrichment{PersonID: 1, Provider: "genderize", Field: "gender", Value: "male", Accepted: true})
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Len(t, person.Enrichments, 2)
+}
+
+// TestPersonHistoryRevert тестирует историю изменений, чтение на момент времени и возврат к версии
+func TestPersonHistoryRevert(t *testing.T) {
+    r, _ := setupRouter()
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    time.Sleep(5 * time.Millisecond)
+    created := time.Now().UTC().Format(time.RFC3339Nano)
+    time.Sleep(5 * time.Millisecond)
+    // Ошибочное изменение
+    req, _ = http.NewRequest("PUT", "/people/1", bytes.NewBuffer([]byte(`{"name":"Иван"}`)))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(ActorHeader, "support:ivanov")
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    // История
+    req, _ = http.NewRequest("GET", "/people/1/history", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var versions []models.PersonVersion
+    json.Unmarshal(w.Body.Bytes(), &versions)
+    assert.Len(t, versions, 2)
+    assert.Equal(t, models.VersionUpdate, versions[1].Operation)
+    assert.Equal(t, "support:ivanov", versions[1].Actor)
+    assert.Equal(t, "Иван", versions[1].Changes["name"].New)
+    req, _ = http.NewRequest("GET", "/people/2/history", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    // Состояние на момент до изменения
+    req, _ = http.NewRequest("GET", "/people/1?as_of="+created, nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var person models.Person
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, "Дмитрий", person.Name)
+    req, _ = http.NewRequest("GET", "/people/1?as_of=вчера", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    // Возврат к первой версии
+    req, _ = http.NewRequest("POST", "/people/1/revert/1", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    json.Unmarshal(w.Body.Bytes(), &person)
+    assert.Equal(t, "Дмитрий", person.Name)
+    req, _ = http.NewRequest("POST", "/people/1/revert/9", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    req, _ = http.NewRequest("POST", "/people/1/revert/abc", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestPersonPublicID тестирует публичный UUID в ответах и маршрутах и приём прежнего числового ID
+func TestPersonPublicID(t *testing.T) {
+    r, _ := setupRouterWithMode(enrichment.ModeAsync)
+    payload := `{"name":"Дмитрий","surname":"Ушаков"}`
+    req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var created map[string]interface{}
+    json.Unmarshal(w.Body.Bytes(), &created)
+    publicID, _ := created["id"].(string)
+    assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, publicID)
+    // UUID в любом регистре и числовой ID указывают на одного человека
+    for _, id := range []string{publicID, strings.ToUpper(publicID), "1"} {
+        req, _ = http.NewRequest("GET", "/people/"+id, nil)
+        w = httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code, id)
+        var person models.Person
+        json.Unmarshal(w.Body.Bytes(), &person)
+        assert.Equal(t, publicID, person.PublicID, id)
+    }
+    req, _ = http.NewRequest("GET", "/people/"+publicID+"/enrichment", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var status models.EnrichmentStatus
+    json.Unmarshal(w.Body.Bytes(), &status)
+    assert.Equal(t, publicID, status.PersonID)
+    // Неизвестный UUID
+    req, _ = http.NewRequest("GET", "/people/01912d68-783e-7a03-8f4e-2c5b9a3d7e10", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    // Удаление и восстановление по UUID
+    req, _ = http.NewRequest("DELETE", "/people/"+publicID, nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("POST", "/people/"+publicID+"/restore", nil)
+    req.Header.Set(AdminTokenHeader, testAdminToken)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestPeopleMemoryRepository тестирует обработчики с хранилищем в памяти вместо базы
+func TestPeopleMemoryRepository(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    people := repository.NewMemoryPersonRepository()
+    enrichers := enrichment.NewRegistry(fakeEnricher{gender: "male", nationality: "RU"})
+    r := gin.Default()
+    r.POST("/people", CreatePerson(people, enrichers, enrichment.ModeSync))
+    r.GET("/people", GetPeople(people, testAdminToken))
+    r.GET("/people/:id", GetPerson(people))
+    r.DELETE("/people/:id", DeletePerson(people))
+    jobs := enrichment.NewJobs()
+    r.GET("/people/:id/enrichment", GetEnrichmentStatus(people))
+    r.POST("/people/:id/enrich", EnrichPerson(people, enrichers))
+    r.POST("/people/enrich", EnrichPeople(people, enrichers, jobs))
+    r.GET("/enrichment/jobs/:id", GetEnrichmentJob(jobs))
+    r.GET("/health", Health(people, enrichers))
+    for _, payload := range []string{`{"name":"Дмитрий","surname":"Ушаков"}`, `{"name":"Иван","surname":"Петров"}`} {
+        req, _ := http.NewRequest("POST", "/people", bytes.NewBuffer([]byte(payload)))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code)
+    }
+    req, _ := http.NewRequest("GET", "/people?surname=ушак", nil)
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var list []models.Person
+    json.Unmarshal(w.Body.Bytes(), &list)
+    assert.Len(t, list, 1)
+    assert.Equal(t, "Дмитрий", list[0].Name)
+    assert.Equal(t, "male", list[0].Gender)
+    // Обогащение работает с тем же хранилищем
+    req, _ = http.NewRequest("POST", "/people/1/enrich", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    records, _ := people.Enrichments(context.Background(), 1)
+    assert.Len(t, records, 2)
+    req, _ = http.NewRequest("GET", "/people/1/enrichment", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var status models.EnrichmentStatus
+    json.Unmarshal(w.Body.Bytes(), &status)
+    assert.Equal(t, models.EnrichmentDone, status.Status)
+    req, _ = http.NewRequest("POST", "/people/enrich", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusAccepted, w.Code)
+    var job enrichment.JobStatus
+    json.Unmarshal(w.Body.Bytes(), &job)
+    assert.Eventually(t, func() bool {
+        req, _ := http.NewRequest("GET", "/enrichment/jobs/"+job.ID, nil)
+        w := httptest.NewRecorder()
+        r.ServeHTTP(w, req)
+        json.Unmarshal(w.Body.Bytes(), &job)
+        return job.Status == enrichment.JobDone
+    }, time.Second, 10*time.Millisecond)
+    assert.Equal(t, 2, job.Processed)
+    assert.Equal(t, 0, job.Failed)
+    req, _ = http.NewRequest("GET", "/health", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("DELETE", "/people/2", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    req, _ = http.NewRequest("GET", "/people/2", nil)
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"io/fs"
 	"os"
 	"person-api/database"
 	_ "person-api/docs" // Импорт Swagger-документации
+	"person-api/enrichment"
 	"person-api/handlers"
+	"person-api/repository"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -20,28 +29,141 @@ import (
 // @BasePath /
 // main запускает REST API сервер с поддержкой Swagger
 func main() {
-	// Загружаем переменные окружения
-	if err := godotenv.Load(); err != nil {
+	// Загружаем переменные окружения; без .env (например, в контейнере) используются переменные процесса
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		logrus.Fatal("Ошибка загрузки .env: ", err)
 	}
 	// Настраиваем логирование
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 	logrus.SetOutput(os.Stdout)
 	logrus.SetLevel(logrus.InfoLevel)
-	// Выполняем миграции
-	logrus.Info("Запуск миграций...")
-	if err := database.RunMigrations(); err != nil {
-		logrus.Fatal("Ошибка миграций: ", err)
+	// Подкоманды: dataset (импорт справочника имён), migrate (управление миграциями)
+	// и serve (запуск сервера, по умолчанию)
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command := args[0]
+		args = args[1:]
+		switch command {
+		case "dataset":
+			if err := runDataset(args); err != nil {
+				logrus.Fatal("Ошибка импорта справочника: ", err)
+			}
+			return
+		case "migrate":
+			if err := runMigrate(args); err != nil {
+				logrus.Fatal("Ошибка миграций: ", err)
+			}
+			return
+		case "serve":
+		default:
+			logrus.Fatalf("Неизвестная команда %q: ожидается serve, migrate или dataset", command)
+		}
 	}
-	// Инициализируем базу данных
-	db := database.InitDB()
+	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+	noMigrate := serveFlags.Bool("no-migrate", false, "не применять миграции при запуске (их выполняет отдельный шаг migrate up)")
+	serveFlags.Parse(args)
+	// Инициализируем базу данных (DB_DRIVER: postgres или sqlite)
+	dbCfg, err := database.LoadConfig()
+	if err != nil {
+		logrus.Fatal("Ошибка настройки базы: ", err)
+	}
+	db := database.InitDB(dbCfg)
+	// Выполняем миграции, если их не вынесли в отдельный шаг развёртывания
+	if *noMigrate {
+		logrus.Info("Автоматические миграции отключены (-no-migrate)")
+	} else {
+		logrus.Info("Запуск миграций...")
+		if err := database.RunMigrations(db); err != nil {
+			logrus.Fatal("Ошибка миграций: ", err)
+		}
+	}
+	// Настраиваем провайдеров обогащения данных
+	httpClient := enrichment.NewHTTPClient()
+	cacheCfg := enrichment.LoadCacheConfig()
+	var cacheStore enrichment.CacheStore
+	if cacheCfg.Persistent {
+		cacheStore = enrichment.NewDBCacheStore(db)
+	}
+	cache := enrichment.NewNameCache(cacheCfg.Size, cacheCfg.TTL, cacheStore)
+	expvar.Publish("enrichment_cache", expvar.Func(func() any { return cache.Stats() }))
+	var providers []enrichment.Enricher
+	// Правила по отчеству и фамилии работают без сети и запускаются раньше внешних API
+	if enrichment.LoadRulesEnabled() {
+		providers = append(providers, enrichment.NewRules())
+	}
+	// Справочник имён — офлайн-замена внешним API (ENRICHMENT_DATASET)
+	datasetCfg := enrichment.LoadDatasetConfig()
+	var dataset *enrichment.DatasetEnricher
+	if datasetCfg.Source != "" {
+		if dataset, err = enrichment.NewDatasetEnricher(datasetCfg); err != nil {
+			logrus.Fatal("Ошибка загрузки справочника имён: ", err)
+		}
+		logrus.Infof("Справочник имён загружен: %d имён", dataset.Status().Names)
+		providers = append(providers, dataset)
+	}
+	if datasetCfg.Offline {
+		logrus.Warn("ENRICHMENT_OFFLINE=true: внешние API обогащения отключены")
+	} else {
+		providers = append(providers,
+			cache.Wrap(enrichment.NewGenderize(httpClient, enrichment.LoadProviderConfig("GENDERIZE", enrichment.DefaultGenderizeConfig))),
+			cache.Wrap(enrichment.NewNationalize(httpClient, enrichment.LoadProviderConfig("NATIONALIZE", enrichment.DefaultNationalizeConfig))),
+			cache.Wrap(enrichment.NewAgify(httpClient, enrichment.LoadProviderConfig("AGIFY", enrichment.DefaultAgifyConfig))),
+		)
+	}
+	enrichers := enrichment.NewRegistry(providers...).WithBudget(enrichment.LoadBudget())
+	expvar.Publish("enrichment_quota", expvar.Func(func() any { return enrichers.Quotas() }))
+	mode, err := enrichment.LoadMode()
+	if err != nil {
+		logrus.Fatal("Ошибка настройки обогащения: ", err)
+	}
+	logrus.Infof("Режим обогащения: %s", mode)
+	// Фоновые воркеры обрабатывают очередь обогащения
+	go enrichment.NewWorkerPool(db, enrichers, enrichment.LoadWorkerConfig()).Run(context.Background())
+	jobs := enrichment.NewJobs()
+	// Хранилище людей и очередь обогащения
+	people := repository.NewGormPersonRepository(db)
+	// Окончательное удаление людей, удалённых дольше срока хранения
+	go repository.RunPurge(context.Background(), people, repository.LoadPurgeConfig())
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	// REQUIRE_IF_MATCH=true: изменение и удаление только с If-Match (защита от потерянных обновлений)
+	requireIfMatch, _ := strconv.ParseBool(os.Getenv("REQUIRE_IF_MATCH"))
+	ifMatch := handlers.RequireIfMatch(requireIfMatch)
+	// Директивы Cache-Control для маршрутов чтения (CACHE_CONTROL_*)
+	cacheControl := handlers.LoadCacheControlConfig()
+	cachePeople := handlers.CacheControl(cacheControl.People)
+	cachePerson := handlers.CacheControl(cacheControl.Person)
+	cacheHistory := handlers.CacheControl(cacheControl.History)
 	// Настраиваем маршруты API
 	r := gin.Default()
-	r.POST("/people", handlers.CreatePerson(db))       // Создание человека
-	r.GET("/people", handlers.GetPeople(db))           // Получение списка людей
-	r.GET("/people/:id", handlers.GetPerson(db))       // Получение человека по ID
-	r.PUT("/people/:id", handlers.UpdatePerson(db))    // Обновление человека
-	r.DELETE("/people/:id", handlers.DeletePerson(db)) // Удаление человека
+	r.Use(handlers.Actor())                                                       // Автор изменений из X-Actor для истории версий
+	r.POST("/people", handlers.CreatePerson(people, enrichers, mode))             // Создание человека
+	r.POST("/people/batch", handlers.CreatePeople(people, enrichers, mode))       // Создание нескольких людей
+	r.GET("/people", cachePeople, handlers.GetPeople(people, adminToken))         // Получение списка людей
+	r.GET("/people/:id", cachePerson, handlers.GetPerson(people))                 // Получение человека по ID
+	r.GET("/people/:id/enrichment", handlers.GetEnrichmentStatus(people))         // Статус обогащения
+	r.POST("/people/:id/enrich", handlers.EnrichPerson(people, enrichers))        // Переобогащение человека
+	r.POST("/people/enrich", handlers.EnrichPeople(people, enrichers, jobs))      // Массовое переобогащение
+	r.GET("/enrichment/jobs/:id", handlers.GetEnrichmentJob(jobs))                // Статус массового переобогащения
+	r.PUT("/people/:id", ifMatch, handlers.UpdatePerson(people))                  // Обновление человека
+	r.DELETE("/people/:id", ifMatch, handlers.DeletePerson(people))               // Удаление человека
+	r.GET("/people/:id/history", cacheHistory, handlers.GetPersonHistory(people)) // История изменений
+	r.POST("/people/:id/revert/:version", handlers.RevertPerson(people))          // Возврат к версии
+	r.GET("/health", handlers.Health(people, enrichers))                          // Состояние сервиса
+	// Административные маршруты доступны только при заданном ADMIN_TOKEN
+	if adminToken != "" {
+		r.POST("/people/:id/restore", handlers.AdminAuth(adminToken), handlers.RestorePerson(people)) // Восстановление удалённого
+		admin := r.Group("/admin", handlers.AdminAuth(adminToken))
+		admin.GET("/enrichment/cache", handlers.GetCacheStats(cache)) // Статистика кэша
+		admin.DELETE("/enrichment/cache", handlers.PurgeCache(cache)) // Очистка кэша
+		admin.GET("/enrichment/quota", handlers.GetQuotas(enrichers)) // Квоты провайдеров
+		admin.GET("/metrics", gin.WrapH(expvar.Handler()))            // Метрики (expvar)
+		if dataset != nil {
+			admin.GET("/enrichment/dataset", handlers.GetDatasetStatus(dataset))      // Состояние справочника
+			admin.POST("/enrichment/dataset/reload", handlers.ReloadDataset(dataset)) // Перечитать справочник
+		}
+	} else {
+		logrus.Warn("ADMIN_TOKEN не задан, административные маршруты отключены")
+	}
 	// Добавляем маршрут для Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	// Определяем порт сервера
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"person-api/database"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrateUsage описание подкоманды migrate
+const migrateUsage = "использование: migrate up [N] | down [N] | version | force ВЕРСИЯ | create ИМЯ"
+
+// runMigrate выполняет подкоманду migrate:
+//
+//	person-api migrate up [N]      применить N следующих миграций (по умолчанию все)
+//	person-api migrate down [N]    откатить N последних миграций (по умолчанию одну)
+//	person-api migrate version     показать текущую версию схемы
+//	person-api migrate force V     записать версию V без выполнения миграций (после сбоя, dirty)
+//	person-api migrate create ИМЯ  создать пустые файлы миграции для всех драйверов
+//
+// Миграции берутся из встроенных в бинарный файл или из MIGRATIONS_DIR; create пишет
+// в MIGRATIONS_DIR (по умолчанию migrations в текущем каталоге).
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+	if command == "create" {
+		if len(args) != 1 {
+			return fmt.Errorf("нужно имя миграции: migrate create ИМЯ")
+		}
+		dir := os.Getenv("MIGRATIONS_DIR")
+		if dir == "" {
+			dir = "migrations"
+		}
+		files, err := database.CreateMigration(dir, args[0])
+		for _, file := range files {
+			logrus.Infof("Создан файл миграции: %s", file)
+		}
+		return err
+	}
+	// Остальные команды работают с базой
+	steps, err := migrateArg(command, args)
+	if err != nil {
+		return err
+	}
+	cfg, err := database.LoadConfig()
+	if err != nil {
+		return err
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	switch command {
+	case "up":
+		err = m.Up(steps)
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		err = m.Down(steps)
+	case "force":
+		err = m.Force(steps)
+	}
+	if err != nil {
+		return err
+	}
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Версия схемы (%s): %d, dirty: %t", cfg.Driver, version, dirty)
+	return nil
+}
+
+// migrateArg проверяет команду и разбирает её числовой аргумент: количество миграций
+// для up/down (0 — по умолчанию) или версию для force
+func migrateArg(command string, args []string) (int, error) {
+	switch command {
+	case "up", "down":
+		if len(args) == 0 {
+			return 0, nil
+		}
+		if len(args) > 1 {
+			return 0, errors.New(migrateUsage)
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("количество миграций должно быть положительным числом: %q", args[0])
+		}
+		return n, nil
+	case "force":
+		if len(args) != 1 {
+			return 0, fmt.Errorf("нужна версия: migrate force ВЕРСИЯ")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return 0, fmt.Errorf("версия должна быть числом не меньше -1: %q", args[0])
+		}
+		return version, nil
+	case "version":
+		if len(args) != 0 {
+			return 0, errors.New(migrateUsage)
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("неизвестная команда migrate %q; %s", command, migrateUsage)
+}
@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции в бинарный файл.
+// Каталоги postgres и sqlite содержат миграции для соответствующего DB_DRIVER.
+package migrations
+
+import "embed"
+
+// FS встроенные миграции: postgres/*.sql и sqlite/*.sql
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
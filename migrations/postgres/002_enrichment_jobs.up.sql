@@ -0,0 +1,15 @@
+ALTER TABLE people ADD COLUMN enrichment_status VARCHAR(20) NOT NULL DEFAULT 'done';
+
+CREATE TABLE enrichment_jobs (
+    id SERIAL PRIMARY KEY,
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_enrichment_jobs_person_id ON enrichment_jobs (person_id);
+CREATE INDEX idx_enrichment_jobs_status_run_after ON enrichment_jobs (status, run_after);
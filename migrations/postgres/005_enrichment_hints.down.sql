@@ -0,0 +1,6 @@
+DELETE FROM enrichment_cache WHERE hint <> '';
+ALTER TABLE enrichment_cache DROP CONSTRAINT enrichment_cache_pkey;
+ALTER TABLE enrichment_cache ADD PRIMARY KEY (provider, name);
+ALTER TABLE enrichment_cache DROP COLUMN hint;
+
+ALTER TABLE person_enrichments DROP COLUMN hint;
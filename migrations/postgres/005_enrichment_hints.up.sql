@@ -0,0 +1,5 @@
+ALTER TABLE person_enrichments ADD COLUMN hint VARCHAR(100) NOT NULL DEFAULT '';
+
+ALTER TABLE enrichment_cache ADD COLUMN hint VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE enrichment_cache DROP CONSTRAINT enrichment_cache_pkey;
+ALTER TABLE enrichment_cache ADD PRIMARY KEY (provider, name, hint);
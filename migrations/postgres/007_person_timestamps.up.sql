@@ -0,0 +1,5 @@
+ALTER TABLE people ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE people ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE people ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_people_deleted_at ON people (deleted_at);
@@ -0,0 +1,13 @@
+CREATE TABLE person_versions (
+    id SERIAL PRIMARY KEY,
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    snapshot TEXT NOT NULL,
+    changes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (person_id, version)
+);
+
+CREATE INDEX idx_person_versions_person_id_created_at ON person_versions (person_id, created_at);
@@ -0,0 +1,2 @@
+-- Номер версии записи для оптимистичной блокировки (ETag / If-Match)
+ALTER TABLE people ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+UPDATE person_versions SET snapshot = jsonb_set(snapshot::jsonb, '{id}', to_jsonb(person_id))::text;
+
+DROP INDEX idx_people_public_id;
+ALTER TABLE people DROP COLUMN public_id;
@@ -0,0 +1,24 @@
+-- Публичный идентификатор (UUID v7) вместо перебираемого SERIAL id в API.
+-- Существующим людям UUID v7 строится из времени создания и случайных бит.
+ALTER TABLE people ADD COLUMN public_id UUID;
+
+UPDATE people SET public_id = (
+    substr(gen.ts, 1, 8) || '-' || substr(gen.ts, 9, 4) || '-7' || substr(gen.r, 1, 3) || '-' ||
+    substr('89ab', ('x' || substr(gen.r, 4, 1))::bit(4)::int % 4 + 1, 1) || substr(gen.r, 5, 3) || '-' ||
+    substr(gen.r, 8, 12)
+)::uuid
+FROM (
+    SELECT id,
+           lpad(to_hex((extract(epoch FROM created_at) * 1000)::bigint), 12, '0') AS ts,
+           md5(random()::text || id::text) AS r
+    FROM people
+) AS gen
+WHERE people.id = gen.id;
+
+ALTER TABLE people ALTER COLUMN public_id SET NOT NULL;
+CREATE UNIQUE INDEX idx_people_public_id ON people (public_id);
+
+-- Снимки в истории версий хранят публичный идентификатор вместо числового
+UPDATE person_versions SET snapshot = jsonb_set(snapshot::jsonb, '{id}', to_jsonb(people.public_id::text))::text
+FROM people
+WHERE people.id = person_versions.person_id;
@@ -0,0 +1,9 @@
+CREATE TABLE people (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    surname VARCHAR(255) NOT NULL,
+    patronymic VARCHAR(255),
+    age INTEGER,
+    gender VARCHAR(50),
+    nationality VARCHAR(50)
+);
@@ -0,0 +1,2 @@
+DROP TABLE enrichment_jobs;
+ALTER TABLE people DROP COLUMN enrichment_status;
@@ -0,0 +1,15 @@
+ALTER TABLE people ADD COLUMN enrichment_status VARCHAR(20) NOT NULL DEFAULT 'done';
+
+CREATE TABLE enrichment_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_after DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_enrichment_jobs_person_id ON enrichment_jobs (person_id);
+CREATE INDEX idx_enrichment_jobs_status_run_after ON enrichment_jobs (status, run_after);
@@ -0,0 +1,11 @@
+CREATE TABLE enrichment_cache (
+    provider VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    gender VARCHAR(50) NOT NULL DEFAULT '',
+    nationality VARCHAR(50) NOT NULL DEFAULT '',
+    age INTEGER,
+    stored_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, name)
+);
+
+CREATE INDEX idx_enrichment_cache_name ON enrichment_cache (name);
@@ -0,0 +1,2 @@
+ALTER TABLE enrichment_cache DROP COLUMN records;
+DROP TABLE person_enrichments;
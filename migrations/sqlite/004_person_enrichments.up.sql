@@ -0,0 +1,17 @@
+CREATE TABLE person_enrichments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    value VARCHAR(255) NOT NULL DEFAULT '',
+    probability DOUBLE PRECISION,
+    count INTEGER,
+    accepted BOOLEAN NOT NULL DEFAULT FALSE,
+    cached BOOLEAN NOT NULL DEFAULT FALSE,
+    summary TEXT NOT NULL DEFAULT '',
+    looked_up_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_person_enrichments_person_id ON person_enrichments (person_id);
+
+ALTER TABLE enrichment_cache ADD COLUMN records TEXT NOT NULL DEFAULT '';
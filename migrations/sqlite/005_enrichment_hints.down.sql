@@ -0,0 +1,17 @@
+CREATE TABLE enrichment_cache_old (
+    provider VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    gender VARCHAR(50) NOT NULL DEFAULT '',
+    nationality VARCHAR(50) NOT NULL DEFAULT '',
+    age INTEGER,
+    stored_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    records TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (provider, name)
+);
+INSERT INTO enrichment_cache_old (provider, name, gender, nationality, age, stored_at, records)
+    SELECT provider, name, gender, nationality, age, stored_at, records FROM enrichment_cache WHERE hint = '';
+DROP TABLE enrichment_cache;
+ALTER TABLE enrichment_cache_old RENAME TO enrichment_cache;
+CREATE INDEX idx_enrichment_cache_name ON enrichment_cache (name);
+
+ALTER TABLE person_enrichments DROP COLUMN hint;
@@ -0,0 +1,19 @@
+ALTER TABLE person_enrichments ADD COLUMN hint VARCHAR(100) NOT NULL DEFAULT '';
+
+-- SQLite не меняет первичный ключ существующей таблицы, поэтому пересоздаём её
+CREATE TABLE enrichment_cache_new (
+    provider VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    hint VARCHAR(100) NOT NULL DEFAULT '',
+    gender VARCHAR(50) NOT NULL DEFAULT '',
+    nationality VARCHAR(50) NOT NULL DEFAULT '',
+    age INTEGER,
+    stored_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    records TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (provider, name, hint)
+);
+INSERT INTO enrichment_cache_new (provider, name, gender, nationality, age, stored_at, records)
+    SELECT provider, name, gender, nationality, age, stored_at, records FROM enrichment_cache;
+DROP TABLE enrichment_cache;
+ALTER TABLE enrichment_cache_new RENAME TO enrichment_cache;
+CREATE INDEX idx_enrichment_cache_name ON enrichment_cache (name);
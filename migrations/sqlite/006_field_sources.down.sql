@@ -0,0 +1,3 @@
+ALTER TABLE people DROP COLUMN nationality_source;
+ALTER TABLE people DROP COLUMN gender_source;
+ALTER TABLE people DROP COLUMN age_source;
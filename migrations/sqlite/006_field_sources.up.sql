@@ -0,0 +1,3 @@
+ALTER TABLE people ADD COLUMN age_source VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE people ADD COLUMN gender_source VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE people ADD COLUMN nationality_source VARCHAR(20) NOT NULL DEFAULT '';
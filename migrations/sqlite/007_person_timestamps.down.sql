@@ -0,0 +1,4 @@
+DROP INDEX idx_people_deleted_at;
+ALTER TABLE people DROP COLUMN deleted_at;
+ALTER TABLE people DROP COLUMN updated_at;
+ALTER TABLE people DROP COLUMN created_at;
@@ -0,0 +1,7 @@
+-- SQLite не добавляет столбцы с DEFAULT CURRENT_TIMESTAMP, поэтому заполняем их отдельно
+ALTER TABLE people ADD COLUMN created_at DATETIME;
+ALTER TABLE people ADD COLUMN updated_at DATETIME;
+ALTER TABLE people ADD COLUMN deleted_at DATETIME;
+UPDATE people SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_people_deleted_at ON people (deleted_at);
@@ -0,0 +1,4 @@
+UPDATE person_versions SET snapshot = json_set(snapshot, '$.id', person_id);
+
+DROP INDEX idx_people_public_id;
+ALTER TABLE people DROP COLUMN public_id;
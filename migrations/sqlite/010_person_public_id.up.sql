@@ -0,0 +1,25 @@
+-- Публичный идентификатор (UUID v7) вместо перебираемого AUTOINCREMENT id в API.
+-- Существующим людям UUID v7 строится из времени создания и случайных бит.
+-- SQLite не добавляет столбцы NOT NULL без значения по умолчанию: обязательность проверяет приложение.
+ALTER TABLE people ADD COLUMN public_id TEXT;
+
+CREATE TEMP TABLE people_public_id AS
+SELECT id,
+       printf('%012x', CAST(round((julianday(created_at) - 2440587.5) * 86400000) AS INTEGER)) AS ts,
+       lower(hex(randomblob(10))) AS r
+FROM people;
+
+UPDATE people SET public_id = (
+    SELECT substr(ts, 1, 8) || '-' || substr(ts, 9, 4) || '-7' || substr(r, 1, 3) || '-' ||
+           substr('89ab', abs(random()) % 4 + 1, 1) || substr(r, 4, 3) || '-' || substr(r, 7, 12)
+    FROM people_public_id
+    WHERE people_public_id.id = people.id
+);
+
+DROP TABLE people_public_id;
+
+CREATE UNIQUE INDEX idx_people_public_id ON people (public_id);
+
+-- Снимки в истории версий хранят публичный идентификатор вместо числового
+UPDATE person_versions SET snapshot = json_set(snapshot, '$.id',
+    (SELECT public_id FROM people WHERE people.id = person_versions.person_id));
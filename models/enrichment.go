@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Статусы обогащения человека и задач очереди
+const (
+	EnrichmentPending = "pending" // Ожидает обработки
+	EnrichmentRunning = "running" // Обрабатывается воркером
+	EnrichmentDone    = "done"    // Завершено
+	EnrichmentFailed  = "failed"  // Завершилось ошибкой
+)
+
+// EnrichmentJob задача фонового обогащения в очереди
+type EnrichmentJob struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`            // Уникальный идентификатор
+	PersonID  uint      `gorm:"not null;index" json:"person_id"` // ID человека
+	Status    string    `gorm:"not null" json:"status"`          // Статус задачи
+	Attempts  int       `gorm:"not null" json:"attempts"`        // Количество попыток
+	LastError string    `gorm:"not null" json:"last_error"`      // Текст последней ошибки
+	RunAfter  time.Time `gorm:"not null;index" json:"run_after"` // Не запускать раньше этого времени
+	CreatedAt time.Time `json:"created_at"`                      // Время постановки в очередь
+	UpdatedAt time.Time `json:"updated_at"`                      // Время последнего изменения
+}
+
+// EnrichmentStatus представляет состояние обогащения человека для ответа API
+type EnrichmentStatus struct {
+	PersonID  string     `json:"person_id" example:"01912d68-783e-7a03-8f4e-2c5b9a3d7e10"` // ID человека
+	Status    string     `json:"status" example:"pending"`                                 // Статус обогащения
+	Attempts  int        `json:"attempts" example:"1"`                                     // Количество попыток
+	LastError string     `json:"last_error,omitempty" example:"таймаут"`                   // Текст последней ошибки
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`                                     // Время последнего изменения
+}
+
+// PersonEnrichment запись о происхождении данных: что ответил провайдер и насколько уверенно
+type PersonEnrichment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`                                        // Уникальный идентификатор
+	PersonID    uint      `gorm:"not null;index" json:"-"`                                     // ID человека
+	Provider    string    `gorm:"not null" json:"provider" example:"genderize"`                // Имя провайдера
+	Field       string    `gorm:"not null" json:"field" example:"gender"`                      // Обогащаемое поле
+	Value       string    `gorm:"not null" json:"value" example:"male"`                        // Предложенное значение
+	Probability *float64  `json:"probability,omitempty" example:"0.98"`                        // Вероятность
+	Count       *int      `json:"count,omitempty" example:"1250"`                              // Размер выборки
+	Accepted    bool      `gorm:"not null" json:"accepted"`                                    // Прошло ли значение порог
+	Cached      bool      `gorm:"not null" json:"cached"`                                      // Взято из кэша
+	Hint        string    `gorm:"not null" json:"hint,omitempty" example:"country_id=RU"`      // Подсказка, переданная провайдеру
+	Summary     string    `gorm:"not null" json:"summary"`                                     // Сводка ответа провайдера
+	LookedUpAt  time.Time `gorm:"not null" json:"looked_up_at" example:"2025-01-01T00:00:00Z"` // Время запроса к провайдеру
+}
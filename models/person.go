@@ -1,14 +1,52 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Источники значений возраста, пола и национальности
+const (
+	SourceClient   = "client"   // Указано клиентом; обогащение его не перезаписывает
+	SourceProvider = "provider" // Определено обогащением
+)
+
 // Person представляет модель человека в базе данных
 type Person struct {
-	ID          uint   `gorm:"primaryKey" json:"id"`    // Уникальный идентификатор
+	// Внутренний идентификатор: в API не отдаётся, но на переходный период принимается в :id
+	ID uint `gorm:"primaryKey" json:"-"`
+	// Публичный идентификатор (UUID v7)
+	PublicID    string `gorm:"uniqueIndex;not null" json:"id" example:"01912d68-783e-7a03-8f4e-2c5b9a3d7e10" format:"uuid"`
 	Name        string `gorm:"not null" json:"name"`    // Имя (обязательное)
 	Surname     string `gorm:"not null" json:"surname"` // Фамилия (обязательная)
 	Patronymic  string `json:"patronymic,omitempty"`    // Отчество (опционально)
 	Age         *int   `json:"age,omitempty"`           // Возраст (опционально)
 	Gender      string `json:"gender,omitempty"`        // Пол (опционально)
 	Nationality string `json:"nationality,omitempty"`   // Национальность (опционально)
+	// Источники значений: client или provider (пусто — неизвестно, для старых записей)
+	AgeSource         string `gorm:"not null;default:''" json:"age_source,omitempty" example:"client"`
+	GenderSource      string `gorm:"not null;default:''" json:"gender_source,omitempty" example:"provider"`
+	NationalitySource string `gorm:"not null;default:''" json:"nationality_source,omitempty" example:"provider"`
+	// Статус обогащения: pending, done или failed
+	EnrichmentStatus string `gorm:"not null" json:"enrichment_status,omitempty"`
+	// Номер версии записи: растёт при каждом изменении, отдаётся в ETag
+	Version int `gorm:"not null;default:1" json:"version" example:"1"`
+	// Время создания и последнего изменения
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Время удаления: удалённые записи скрыты и окончательно стираются по истечении срока хранения
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero" swaggertype:"string" format:"date-time"`
+	// Происхождение обогащённых данных (только при ?include=enrichment)
+	Enrichments []PersonEnrichment `gorm:"foreignKey:PersonID;constraint:OnDelete:CASCADE" json:"enrichment,omitempty"`
+}
+
+// BeforeCreate присваивает новому человеку публичный идентификатор
+func (p *Person) BeforeCreate(tx *gorm.DB) error {
+	if p.PublicID == "" {
+		p.PublicID = NewUUIDv7()
+	}
+	return nil
 }
 
 // ErrorResponse представляет структуру ошибки для ответа API
@@ -20,3 +58,24 @@ type ErrorResponse struct {
 type MessageResponse struct {
 	Message string `json:"message" example:"Удалён"`
 }
+
+// FieldSource возвращает источник значения поля age, gender или nationality
+func (p *Person) FieldSource(field string) string {
+	switch field {
+	case "age":
+		return p.AgeSource
+	case "gender":
+		return p.GenderSource
+	case "nationality":
+		return p.NationalitySource
+	}
+	return ""
+}
+
+// SameAge сравнивает возраст по значению: равные возрасты могут лежать в разных указателях
+func SameAge(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
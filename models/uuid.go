@@ -0,0 +1,61 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// NewUUIDv7 возвращает UUID версии 7 (RFC 9562): первые 48 бит — время в миллисекундах
+// с начала эпохи Unix, остальное — случайные биты из crypto/rand. Такие идентификаторы
+// упорядочены по времени создания (удобно для индекса), но не раскрывают количество записей.
+func NewUUIDv7() string {
+	var b [16]byte
+	rand.Read(b[6:])
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ms[2:])
+	b[6] = b[6]&0x0f | 0x70 // Версия 7
+	b[8] = b[8]&0x3f | 0x80 // Вариант RFC 9562
+	return formatUUID(b)
+}
+
+// formatUUID записывает UUID в каноническом виде xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func formatUUID(b [16]byte) string {
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// ParseUUID проверяет, что s — UUID в каноническом виде, и возвращает его в нижнем регистре
+func ParseUUID(s string) (string, bool) {
+	if len(s) != 36 {
+		return "", false
+	}
+	var b [16]byte
+	src := []byte(s)
+	for i, j := 0, 0; i < len(src); {
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if src[i] != '-' {
+				return "", false
+			}
+			i++
+			continue
+		}
+		if _, err := hex.Decode(b[j:j+1], src[i:i+2]); err != nil {
+			return "", false
+		}
+		i += 2
+		j++
+	}
+	return formatUUID(b), true
+}
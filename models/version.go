@@ -0,0 +1,80 @@
+package models
+
+import (
+	"reflect"
+	"time"
+)
+
+// Операции в истории изменений человека
+const (
+	VersionCreate  = "create"  // Создание
+	VersionUpdate  = "update"  // Изменение через API
+	VersionEnrich  = "enrich"  // Изменение обогащением
+	VersionDelete  = "delete"  // Удаление
+	VersionRestore = "restore" // Восстановление после удаления
+	VersionRevert  = "revert"  // Возврат к прежней версии
+)
+
+// FieldChange изменение одного поля: значение до и после
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// PersonVersion версия человека в истории изменений: снимок после операции и изменённые поля
+type PersonVersion struct {
+	ID        uint                   `gorm:"primaryKey" json:"-"`                                         // Уникальный идентификатор
+	PersonID  uint                   `gorm:"not null;index" json:"-"`                                     // ID человека
+	Version   int                    `gorm:"not null" json:"version" example:"2"`                         // Номер версии (с 1)
+	Operation string                 `gorm:"not null" json:"operation" example:"update"`                  // Операция
+	Actor     string                 `gorm:"not null" json:"actor,omitempty" example:"support:ivanov"`    // Кто изменил (заголовок X-Actor)
+	Snapshot  Person                 `gorm:"serializer:json;type:text;not null" json:"snapshot"`          // Состояние после операции
+	Changes   map[string]FieldChange `gorm:"serializer:json;type:text;not null" json:"changes,omitempty"` // Изменённые поля
+	CreatedAt time.Time              `gorm:"not null" json:"created_at" example:"2025-01-01T00:00:00Z"`   // Время изменения
+}
+
+// versionedFields значения полей человека, которые отслеживаются в истории
+func versionedFields(p Person) map[string]interface{} {
+	var age interface{}
+	if p.Age != nil {
+		age = *p.Age
+	}
+	var deletedAt interface{}
+	if p.DeletedAt.Valid {
+		deletedAt = p.DeletedAt.Time
+	}
+	return map[string]interface{}{
+		"name":               p.Name,
+		"surname":            p.Surname,
+		"patronymic":         p.Patronymic,
+		"age":                age,
+		"gender":             p.Gender,
+		"nationality":        p.Nationality,
+		"age_source":         p.AgeSource,
+		"gender_source":      p.GenderSource,
+		"nationality_source": p.NationalitySource,
+		"enrichment_status":  p.EnrichmentStatus,
+		"deleted_at":         deletedAt,
+	}
+}
+
+// DiffPeople возвращает изменённые поля между before и after.
+// Для нового человека (before == nil) — все заполненные поля.
+func DiffPeople(before *Person, after Person) map[string]FieldChange {
+	newValues := versionedFields(after)
+	var oldValues map[string]interface{}
+	if before != nil {
+		oldValues = versionedFields(*before)
+	}
+	changes := map[string]FieldChange{}
+	for field, value := range newValues {
+		old := oldValues[field]
+		if before == nil && (value == nil || value == "") {
+			continue
+		}
+		if !reflect.DeepEqual(old, value) {
+			changes[field] = FieldChange{Old: old, New: value}
+		}
+	}
+	return changes
+}
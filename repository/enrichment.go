@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"person-api/models"
+	"time"
+)
+
+// EnrichmentActor автор изменений обогащения в истории версий
+const EnrichmentActor = "enrichment"
+
+// EnrichmentRepository хранилище результатов и очереди фонового обогащения
+type EnrichmentRepository interface {
+	// SaveEnrichment сохраняет результат обогащения after, полученный из состояния before:
+	// только изменённые поля (чтобы не затереть параллельные правки) и новые записи
+	// о происхождении. Поле пишется, только если в хранилище оно не помечено как указанное
+	// клиентом, даже если клиент изменил его, пока шло обогащение. Непустой status
+	// записывается в статус обогащения. Изменения записываются в историю версий от имени
+	// EnrichmentActor и увеличивают версию записи. Удалённого человека пропускает.
+	SaveEnrichment(ctx context.Context, before, after models.Person, status string) error
+	// Enqueue ставит человека в очередь фонового обогащения на время runAfter;
+	// внутри Transaction — в той же транзакции, чтобы задача не потерялась.
+	// Если у человека уже есть ожидающая задача, вторая не создаётся:
+	// ожидающая лишь запускается не позже runAfter.
+	Enqueue(ctx context.Context, personID uint, runAfter time.Time) error
+	// EnrichmentStatus возвращает состояние обогащения человека по последней задаче в очереди.
+	// Если задач нет (синхронный режим), используется статус из самой записи.
+	EnrichmentStatus(ctx context.Context, person models.Person) (models.EnrichmentStatus, error)
+}
+
+// enrichedFields поля, которые заполняет обогащение
+var enrichedFields = []string{"gender", "nationality", "age"}
+
+// changedFields возвращает обогащаемые поля, значение или источник которых изменились
+func changedFields(before, after models.Person) []string {
+	var fields []string
+	for _, field := range enrichedFields {
+		if !sameFieldValue(before, after, field) || before.FieldSource(field) != after.FieldSource(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// sameFieldValue сравнивает значения обогащаемого поля (возраст — по значению, а не по указателю)
+func sameFieldValue(before, after models.Person, field string) bool {
+	if field == "age" {
+		return models.SameAge(before.Age, after.Age)
+	}
+	return fieldValue(before, field) == fieldValue(after, field)
+}
+
+// fieldValue возвращает значение обогащаемого поля
+func fieldValue(p models.Person, field string) interface{} {
+	switch field {
+	case "gender":
+		return p.Gender
+	case "nationality":
+		return p.Nationality
+	default:
+		return p.Age
+	}
+}
+
+// copyField переносит обогащаемое поле и его источник из src в dst
+func copyField(dst *models.Person, src models.Person, field string) {
+	switch field {
+	case "gender":
+		dst.Gender, dst.GenderSource = src.Gender, src.GenderSource
+	case "nationality":
+		dst.Nationality, dst.NationalitySource = src.Nationality, src.NationalitySource
+	default:
+		dst.Age, dst.AgeSource = src.Age, src.AgeSource
+	}
+}
+
+// jobStatus дополняет статус записи состоянием последней задачи очереди (если она есть)
+func jobStatus(person models.Person, job *models.EnrichmentJob) models.EnrichmentStatus {
+	status := models.EnrichmentStatus{PersonID: person.PublicID, Status: person.EnrichmentStatus}
+	if job != nil {
+		status.Status = job.Status
+		status.Attempts = job.Attempts
+		status.LastError = job.LastError
+		updatedAt := job.UpdatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	return status
+}
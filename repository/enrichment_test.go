@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"person-api/database"
+	"person-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveEnrichmentKeepsClientFields проверяет, что результат обогащения не затирает поле,
+// которое клиент указал, пока шло обогащение, и записывается версией от имени обогащения
+func TestSaveEnrichmentKeepsClientFields(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков", EnrichmentStatus: models.EnrichmentPending}
+			require.NoError(t, repo.Create(ctx, &person))
+			before := person
+			after := person
+			after.Gender, after.GenderSource = "female", models.SourceProvider
+			after.Nationality, after.NationalitySource = "RU", models.SourceProvider
+			after.Enrichments = []models.PersonEnrichment{{Provider: "nationalize", Field: "nationality", Value: "RU", Accepted: true}}
+			// Клиент успел указать пол вручную
+			edited := person
+			edited.Gender, edited.GenderSource = "male", models.SourceClient
+			require.NoError(t, repo.Update(ctx, &edited))
+
+			require.NoError(t, repo.SaveEnrichment(ctx, before, after, models.EnrichmentDone))
+			saved, err := repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "male", saved.Gender)
+			assert.Equal(t, models.SourceClient, saved.GenderSource)
+			assert.Equal(t, "RU", saved.Nationality)
+			assert.Equal(t, models.SourceProvider, saved.NationalitySource)
+			assert.Equal(t, models.EnrichmentDone, saved.EnrichmentStatus)
+			assert.Equal(t, 3, saved.Version)
+
+			records, err := repo.Enrichments(ctx, person.ID)
+			require.NoError(t, err)
+			if assert.Len(t, records, 1) {
+				assert.Equal(t, "nationalize", records[0].Provider)
+			}
+			versions, err := repo.History(ctx, person.ID)
+			require.NoError(t, err)
+			require.Len(t, versions, 3)
+			assert.Equal(t, models.VersionEnrich, versions[2].Operation)
+			assert.Equal(t, EnrichmentActor, versions[2].Actor)
+			assert.NotContains(t, versions[2].Changes, "gender")
+
+			// Человека удалили, пока шло обогащение
+			require.NoError(t, repo.Delete(ctx, person.ID, 0))
+			assert.NoError(t, repo.SaveEnrichment(ctx, before, after, models.EnrichmentDone))
+		})
+	}
+}
+
+// TestEnrichmentRepositoryQueue проверяет постановку в очередь и статус обогащения
+func TestEnrichmentRepositoryQueue(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков", EnrichmentStatus: models.EnrichmentDone}
+			require.NoError(t, repo.Create(ctx, &person))
+			// Без задач — статус из самой записи
+			status, err := repo.EnrichmentStatus(ctx, person)
+			require.NoError(t, err)
+			assert.Equal(t, person.PublicID, status.PersonID)
+			assert.Equal(t, models.EnrichmentDone, status.Status)
+			assert.Nil(t, status.UpdatedAt)
+
+			require.NoError(t, repo.Enqueue(ctx, person.ID, time.Now()))
+			status, err = repo.EnrichmentStatus(ctx, person)
+			require.NoError(t, err)
+			assert.Equal(t, models.EnrichmentPending, status.Status)
+			assert.Equal(t, 0, status.Attempts)
+			assert.NotNil(t, status.UpdatedAt)
+		})
+	}
+}
+
+// TestChangedFieldsAge проверяет, что равный возраст в другом указателе не считается изменением
+func TestChangedFieldsAge(t *testing.T) {
+	age, other := 42, 42
+	assert.Empty(t, changedFields(models.Person{Age: &age}, models.Person{Age: &other}))
+	other = 43
+	assert.Equal(t, []string{"age"}, changedFields(models.Person{Age: &age}, models.Person{Age: &other}))
+	assert.Equal(t, []string{"age"}, changedFields(models.Person{}, models.Person{Age: &other}))
+}
+
+// TestEnqueueKeepsOnePendingJob проверяет, что повторная постановка в очередь не создаёт
+// вторую ожидающую задачу, а только переносит запуск на более раннее время
+func TestEnqueueKeepsOnePendingJob(t *testing.T) {
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, Path: ":memory:"})
+	require.NoError(t, err)
+	require.NoError(t, database.RunMigrations(db))
+	memory := NewMemoryPersonRepository()
+	// pendingJobs возвращает ожидающие задачи человека из хранилища
+	pendingJobs := map[string]func(id uint) []models.EnrichmentJob{
+		"gorm": func(id uint) []models.EnrichmentJob {
+			var jobs []models.EnrichmentJob
+			require.NoError(t, db.Where("person_id = ? AND status = ?", id, models.EnrichmentPending).Find(&jobs).Error)
+			return jobs
+		},
+		"memory": func(id uint) []models.EnrichmentJob {
+			return memory.jobs[id]
+		},
+	}
+	repos := map[string]PersonRepository{"gorm": NewGormPersonRepository(db), "memory": memory}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков"}
+			require.NoError(t, repo.Create(ctx, &person))
+			now := time.Now()
+			require.NoError(t, repo.Enqueue(ctx, person.ID, now.Add(time.Hour)))
+			require.NoError(t, repo.Enqueue(ctx, person.ID, now.Add(2*time.Hour)))
+			require.NoError(t, repo.Enqueue(ctx, person.ID, now))
+
+			jobs := pendingJobs[name](person.ID)
+			require.Len(t, jobs, 1)
+			assert.WithinDuration(t, now, jobs[0].RunAfter, time.Second)
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"person-api/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Conn возвращает транзакцию, открытую Transaction, или db, если ctx вне транзакции.
+// Позволяет другим пакетам (например, очереди обогащения) писать в ту же транзакцию.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// GormPersonRepository хранит людей в базе через GORM
+type GormPersonRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPersonRepository создаёт хранилище людей в базе
+func NewGormPersonRepository(db *gorm.DB) *GormPersonRepository {
+	return &GormPersonRepository{db: db}
+}
+
+// Create сохраняет нового человека вместе с записями о происхождении и первой версией
+func (r *GormPersonRepository) Create(ctx context.Context, person *models.Person) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		if err := tx.Create(person).Error; err != nil {
+			return err
+		}
+		return RecordVersion(tx, models.VersionCreate, Actor(ctx), nil, *person)
+	})
+}
+
+// Get возвращает человека по ID
+func (r *GormPersonRepository) Get(ctx context.Context, id uint) (*models.Person, error) {
+	return find(Conn(ctx, r.db), id)
+}
+
+// FindID возвращает внутренний ID человека по публичному идентификатору
+func (r *GormPersonRepository) FindID(ctx context.Context, publicID string) (uint, error) {
+	var ids []uint
+	err := Conn(ctx, r.db).Unscoped().Model(&models.Person{}).Where("public_id = ?", publicID).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, ErrNotFound
+	}
+	return ids[0], nil
+}
+
+// find загружает человека по ID запросом db (с Unscoped — в том числе удалённого)
+func find(db *gorm.DB, id uint) (*models.Person, error) {
+	var person models.Person
+	if err := db.First(&person, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &person, nil
+}
+
+// List возвращает людей по фильтру
+func (r *GormPersonRepository) List(ctx context.Context, filter PersonFilter) ([]models.Person, error) {
+	query := r.filter(ctx, filter).Order("id").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var people []models.Person
+	if err := query.Find(&people).Error; err != nil {
+		return nil, err
+	}
+	return people, nil
+}
+
+// Count возвращает количество людей по фильтру
+func (r *GormPersonRepository) Count(ctx context.Context, filter PersonFilter) (int64, error) {
+	var count int64
+	err := r.filter(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// LastModified возвращает наибольшее updated_at людей по фильтру. Берётся сам столбец, а не MAX():
+// так SQLite сохраняет его тип и значение читается как время.
+func (r *GormPersonRepository) LastModified(ctx context.Context, filter PersonFilter) (time.Time, error) {
+	var times []time.Time
+	err := r.filter(ctx, filter).Order("updated_at DESC").Limit(1).Pluck("updated_at", &times).Error
+	if err != nil || len(times) == 0 {
+		return time.Time{}, err
+	}
+	return times[0], nil
+}
+
+// filter строит запрос с условиями фильтра (без пагинации)
+func (r *GormPersonRepository) filter(ctx context.Context, filter PersonFilter) *gorm.DB {
+	query := Conn(ctx, r.db).Model(&models.Person{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Name != "" {
+		query = r.containsFold(query, "name", filter.Name)
+	}
+	if filter.Surname != "" {
+		query = r.containsFold(query, "surname", filter.Surname)
+	}
+	if filter.Age != nil {
+		query = query.Where("age = ?", *filter.Age)
+	}
+	if filter.Gender != "" {
+		query = query.Where("gender = ?", filter.Gender)
+	}
+	if filter.Nationality != "" {
+		query = query.Where("nationality = ?", filter.Nationality)
+	}
+	return query
+}
+
+// containsFold добавляет условие «column содержит value без учёта регистра».
+// В PostgreSQL это ILIKE; в SQLite — сравнение через lower(), которую пакет database
+// переопределяет для Unicode (встроенная lower понимает только ASCII).
+func (r *GormPersonRepository) containsFold(query *gorm.DB, column, value string) *gorm.DB {
+	if r.db.Dialector.Name() == "postgres" {
+		return query.Where(column+" ILIKE ?", "%"+value+"%")
+	}
+	return query.Where("lower("+column+") LIKE ?", "%"+strings.ToLower(value)+"%")
+}
+
+// Update сохраняет все поля человека, если его версия не изменилась, и записывает версию в историю
+func (r *GormPersonRepository) Update(ctx context.Context, person *models.Person) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		before, err := find(tx, person.ID)
+		if err != nil {
+			return err
+		}
+		if before.Version != person.Version {
+			return ErrConflict
+		}
+		if err := save(tx, person); err != nil {
+			return err
+		}
+		return RecordVersion(tx, models.VersionUpdate, Actor(ctx), before, *person)
+	})
+}
+
+// save сохраняет все поля человека и увеличивает версию. Условие на прежнюю версию
+// не даёт затереть запись, изменённую параллельной транзакцией (тогда ErrConflict).
+func save(tx *gorm.DB, person *models.Person) error {
+	version := person.Version
+	person.Version++
+	result := tx.Model(person).Where("version = ?", version).
+		Select("*").Omit("created_at", "deleted_at", "Enrichments").Updates(person)
+	if result.Error != nil {
+		person.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		person.Version = version
+		return ErrConflict
+	}
+	return nil
+}
+
+// Delete помечает человека удалённым (deleted_at)
+func (r *GormPersonRepository) Delete(ctx context.Context, id uint, version int) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		before, err := find(tx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && before.Version != version {
+			return ErrConflict
+		}
+		result := tx.Model(&models.Person{}).Where("id = ? AND version = ?", id, before.Version).
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Запись успели удалить или изменить параллельным запросом
+			if _, err := find(tx, id); err != nil {
+				return err
+			}
+			return ErrConflict
+		}
+		return r.recordCurrent(tx, models.VersionDelete, Actor(ctx), before)
+	})
+}
+
+// Restore снимает отметку об удалении
+func (r *GormPersonRepository) Restore(ctx context.Context, id uint) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		before, err := find(tx.Unscoped().Where("deleted_at IS NOT NULL"), id)
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Person{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		return r.recordCurrent(tx, models.VersionRestore, Actor(ctx), before)
+	})
+}
+
+// recordCurrent перечитывает человека после изменения и записывает версию
+func (r *GormPersonRepository) recordCurrent(tx *gorm.DB, operation, actor string, before *models.Person) error {
+	after, err := find(tx.Unscoped(), before.ID)
+	if err != nil {
+		return err
+	}
+	return RecordVersion(tx, operation, actor, before, *after)
+}
+
+// History возвращает версии человека по возрастанию номера
+func (r *GormPersonRepository) History(ctx context.Context, id uint) ([]models.PersonVersion, error) {
+	var versions []models.PersonVersion
+	err := Conn(ctx, r.db).Where("person_id = ?", id).Order("version").Find(&versions).Error
+	return versions, err
+}
+
+// AsOf возвращает состояние человека на момент at по последней версии не позже at
+func (r *GormPersonRepository) AsOf(ctx context.Context, id uint, at time.Time) (*models.Person, error) {
+	var version models.PersonVersion
+	err := Conn(ctx, r.db).Where("person_id = ? AND created_at <= ?", id, at.UTC()).
+		Order("version DESC").First(&version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshotPerson(version)
+}
+
+// Revert возвращает поля человека к снимку версии number и записывает новую версию
+func (r *GormPersonRepository) Revert(ctx context.Context, id uint, number int) (*models.Person, error) {
+	var person *models.Person
+	err := r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		before, err := find(tx, id)
+		if err != nil {
+			return err
+		}
+		var version models.PersonVersion
+		err = tx.Where("person_id = ? AND version = ?", id, number).First(&version).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		after := *before
+		applySnapshot(&after, version.Snapshot)
+		if err := save(tx, &after); err != nil {
+			return err
+		}
+		person = &after
+		return RecordVersion(tx, models.VersionRevert, Actor(ctx), before, after)
+	})
+	return person, err
+}
+
+// Purge окончательно удаляет людей, удалённых раньше before; записи о происхождении
+// и задачи обогащения удаляются каскадно
+func (r *GormPersonRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := Conn(ctx, r.db).Unscoped().Where("deleted_at < ?", before).Delete(&models.Person{})
+	return result.RowsAffected, result.Error
+}
+
+// Enrichments возвращает записи о происхождении данных человека
+func (r *GormPersonRepository) Enrichments(ctx context.Context, id uint) ([]models.PersonEnrichment, error) {
+	var records []models.PersonEnrichment
+	err := Conn(ctx, r.db).Where("person_id = ?", id).Order("looked_up_at, id").Find(&records).Error
+	return records, err
+}
+
+// SaveEnrichment сохраняет результат обогащения и записывает версию от имени EnrichmentActor
+func (r *GormPersonRepository) SaveEnrichment(ctx context.Context, before, after models.Person, status string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := Conn(ctx, r.db)
+		current, err := find(tx, after.ID)
+		if errors.Is(err, ErrNotFound) {
+			// Человека удалили, пока шло обогащение
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, field := range changedFields(before, after) {
+			err := tx.Model(&models.Person{}).
+				Where("id = ? AND "+field+"_source <> ?", after.ID, models.SourceClient).
+				Updates(map[string]interface{}{
+					field:             fieldValue(after, field),
+					field + "_source": after.FieldSource(field),
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if status != "" {
+			err := tx.Model(&models.Person{}).Where("id = ?", after.ID).Update("enrichment_status", status).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(after.Enrichments) > len(before.Enrichments) {
+			records := after.Enrichments[len(before.Enrichments):]
+			for i := range records {
+				records[i].PersonID = after.ID
+			}
+			if err := tx.Create(&records).Error; err != nil {
+				return err
+			}
+		}
+		saved, err := find(tx, after.ID)
+		if err != nil {
+			return err
+		}
+		if len(models.DiffPeople(current, *saved)) == 0 {
+			return nil
+		}
+		// Запись изменилась: прежний ETag клиентов больше не действителен
+		err = tx.Model(&models.Person{}).Where("id = ?", after.ID).
+			UpdateColumn("version", gorm.Expr("version + 1")).Error
+		if err != nil {
+			return err
+		}
+		saved.Version++
+		return RecordVersion(tx, models.VersionEnrich, EnrichmentActor, current, *saved)
+	})
+}
+
+// Enqueue добавляет задачу в таблицу enrichment_jobs или переносит уже ожидающую на runAfter,
+// если это раньше
+func (r *GormPersonRepository) Enqueue(ctx context.Context, personID uint, runAfter time.Time) error {
+	tx := Conn(ctx, r.db)
+	var pending []models.EnrichmentJob
+	err := tx.Where("person_id = ? AND status = ?", personID, models.EnrichmentPending).
+		Order("id").Limit(1).Find(&pending).Error
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		if !runAfter.Before(pending[0].RunAfter) {
+			return nil
+		}
+		return tx.Model(&pending[0]).Update("run_after", runAfter).Error
+	}
+	job := models.EnrichmentJob{
+		PersonID: personID,
+		Status:   models.EnrichmentPending,
+		RunAfter: runAfter,
+	}
+	return tx.Create(&job).Error
+}
+
+// EnrichmentStatus возвращает состояние обогащения по последней задаче в enrichment_jobs
+func (r *GormPersonRepository) EnrichmentStatus(ctx context.Context, person models.Person) (models.EnrichmentStatus, error) {
+	var jobs []models.EnrichmentJob
+	err := Conn(ctx, r.db).Where("person_id = ?", person.ID).Order("id DESC").Limit(1).Find(&jobs).Error
+	if err != nil {
+		return jobStatus(person, nil), err
+	}
+	if len(jobs) == 0 {
+		return jobStatus(person, nil), nil
+	}
+	return jobStatus(person, &jobs[0]), nil
+}
+
+// Ping проверяет соединение с базой
+func (r *GormPersonRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Transaction выполняет fn в транзакции базы
+func (r *GormPersonRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
@@ -0,0 +1,396 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"person-api/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryPersonRepository хранит людей в памяти процесса (для тестов и локального запуска).
+// Транзакции выполняются по очереди и при ошибке откатывают изменения,
+// но не изолированы от вызовов вне транзакции.
+type MemoryPersonRepository struct {
+	mu          sync.RWMutex
+	txMu        sync.Mutex
+	nextID      uint
+	people      map[uint]models.Person
+	enrichments map[uint][]models.PersonEnrichment
+	versions    map[uint][]models.PersonVersion
+	jobs        map[uint][]models.EnrichmentJob
+	nextJobID   uint
+}
+
+// NewMemoryPersonRepository создаёт пустое хранилище в памяти
+func NewMemoryPersonRepository() *MemoryPersonRepository {
+	return &MemoryPersonRepository{
+		people:      make(map[uint]models.Person),
+		enrichments: make(map[uint][]models.PersonEnrichment),
+		versions:    make(map[uint][]models.PersonVersion),
+		jobs:        make(map[uint][]models.EnrichmentJob),
+	}
+}
+
+// Create сохраняет нового человека и присваивает ему ID
+func (r *MemoryPersonRepository) Create(ctx context.Context, person *models.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	person.ID = r.nextID
+	now := time.Now()
+	if person.CreatedAt.IsZero() {
+		person.CreatedAt = now
+	}
+	person.UpdatedAt = now
+	person.Version = 1
+	if person.PublicID == "" {
+		person.PublicID = models.NewUUIDv7()
+	}
+	for i := range person.Enrichments {
+		person.Enrichments[i].PersonID = person.ID
+		person.Enrichments[i].ID = uint(i + 1)
+	}
+	if len(person.Enrichments) > 0 {
+		r.enrichments[person.ID] = slices.Clone(person.Enrichments)
+	}
+	r.people[person.ID] = stored(*person)
+	r.record(ctx, models.VersionCreate, nil, *person)
+	return nil
+}
+
+// record добавляет версию человека; вызывается под r.mu
+func (r *MemoryPersonRepository) record(ctx context.Context, operation string, before *models.Person, after models.Person) {
+	versions := r.versions[after.ID]
+	if version, ok := newVersion(operation, Actor(ctx), before, stored(after), len(versions)+1); ok {
+		r.versions[after.ID] = append(versions, version)
+	}
+}
+
+// Get возвращает копию человека по ID
+func (r *MemoryPersonRepository) Get(ctx context.Context, id uint) (*models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	person, ok := r.people[id]
+	if !ok || person.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	person = stored(person)
+	return &person, nil
+}
+
+// FindID возвращает внутренний ID человека по публичному идентификатору
+func (r *MemoryPersonRepository) FindID(ctx context.Context, publicID string) (uint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for id, person := range r.people {
+		if person.PublicID == publicID {
+			return id, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// List возвращает людей по фильтру в порядке ID
+func (r *MemoryPersonRepository) List(ctx context.Context, filter PersonFilter) ([]models.Person, error) {
+	people := r.matching(filter)
+	if filter.Offset >= len(people) {
+		return []models.Person{}, nil
+	}
+	people = people[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(people) {
+		people = people[:filter.Limit]
+	}
+	return people, nil
+}
+
+// Count возвращает количество людей по фильтру
+func (r *MemoryPersonRepository) Count(ctx context.Context, filter PersonFilter) (int64, error) {
+	return int64(len(r.matching(filter))), nil
+}
+
+// LastModified возвращает наибольшее время изменения людей по фильтру
+func (r *MemoryPersonRepository) LastModified(ctx context.Context, filter PersonFilter) (time.Time, error) {
+	var last time.Time
+	for _, person := range r.matching(filter) {
+		if person.UpdatedAt.After(last) {
+			last = person.UpdatedAt
+		}
+	}
+	return last, nil
+}
+
+// matching возвращает всех людей, подходящих под фильтр, отсортированных по ID
+func (r *MemoryPersonRepository) matching(filter PersonFilter) []models.Person {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var people []models.Person
+	for _, id := range slices.Sorted(maps.Keys(r.people)) {
+		p := r.people[id]
+		if p.DeletedAt.Valid && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Name != "" && !containsFold(p.Name, filter.Name) {
+			continue
+		}
+		if filter.Surname != "" && !containsFold(p.Surname, filter.Surname) {
+			continue
+		}
+		if filter.Age != nil && (p.Age == nil || *p.Age != *filter.Age) {
+			continue
+		}
+		if filter.Gender != "" && p.Gender != filter.Gender {
+			continue
+		}
+		if filter.Nationality != "" && p.Nationality != filter.Nationality {
+			continue
+		}
+		people = append(people, stored(p))
+	}
+	return people
+}
+
+// containsFold сообщает, содержит ли s подстроку substr без учёта регистра
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// Update сохраняет все поля существующего человека
+func (r *MemoryPersonRepository) Update(ctx context.Context, person *models.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.people[person.ID]
+	if !ok || current.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if current.Version != person.Version {
+		return ErrConflict
+	}
+	person.Version++
+	person.UpdatedAt = time.Now()
+	r.people[person.ID] = stored(*person)
+	r.record(ctx, models.VersionUpdate, &current, *person)
+	return nil
+}
+
+// Delete помечает человека удалённым
+func (r *MemoryPersonRepository) Delete(ctx context.Context, id uint, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	person, ok := r.people[id]
+	if !ok || person.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if version != 0 && person.Version != version {
+		return ErrConflict
+	}
+	before := person
+	now := time.Now()
+	person.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	person.UpdatedAt = now
+	person.Version++
+	r.people[id] = person
+	r.record(ctx, models.VersionDelete, &before, person)
+	return nil
+}
+
+// Restore снимает отметку об удалении
+func (r *MemoryPersonRepository) Restore(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	person, ok := r.people[id]
+	if !ok || !person.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	before := person
+	person.DeletedAt = gorm.DeletedAt{}
+	person.Version++
+	person.UpdatedAt = time.Now()
+	r.people[id] = person
+	r.record(ctx, models.VersionRestore, &before, person)
+	return nil
+}
+
+// Purge окончательно удаляет людей, удалённых раньше before, вместе с записями о происхождении
+// и задачами обогащения
+func (r *MemoryPersonRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged int64
+	for id, person := range r.people {
+		if person.DeletedAt.Valid && person.DeletedAt.Time.Before(before) {
+			delete(r.people, id)
+			delete(r.enrichments, id)
+			delete(r.versions, id)
+			delete(r.jobs, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// History возвращает версии человека по возрастанию номера
+func (r *MemoryPersonRepository) History(ctx context.Context, id uint) ([]models.PersonVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.versions[id]), nil
+}
+
+// AsOf возвращает состояние человека на момент at по последней версии не позже at
+func (r *MemoryPersonRepository) AsOf(ctx context.Context, id uint, at time.Time) (*models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.versions[id]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].CreatedAt.After(at) {
+			return snapshotPerson(versions[i])
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Revert возвращает поля человека к снимку версии number и записывает новую версию
+func (r *MemoryPersonRepository) Revert(ctx context.Context, id uint, number int) (*models.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before, ok := r.people[id]
+	if !ok || before.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	versions := r.versions[id]
+	if number < 1 || number > len(versions) {
+		return nil, ErrVersionNotFound
+	}
+	after := before
+	applySnapshot(&after, versions[number-1].Snapshot)
+	after.Version++
+	after.UpdatedAt = time.Now()
+	r.people[id] = stored(after)
+	r.record(ctx, models.VersionRevert, &before, after)
+	after = stored(after)
+	return &after, nil
+}
+
+// Enrichments возвращает записи о происхождении данных человека
+func (r *MemoryPersonRepository) Enrichments(ctx context.Context, id uint) ([]models.PersonEnrichment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.enrichments[id]), nil
+}
+
+// SaveEnrichment сохраняет результат обогащения и записывает версию от имени EnrichmentActor
+func (r *MemoryPersonRepository) SaveEnrichment(ctx context.Context, before, after models.Person, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.people[after.ID]
+	if !ok || current.DeletedAt.Valid {
+		// Человека удалили, пока шло обогащение
+		return nil
+	}
+	saved := stored(current)
+	for _, field := range changedFields(before, after) {
+		if current.FieldSource(field) != models.SourceClient {
+			copyField(&saved, after, field)
+		}
+	}
+	if status != "" {
+		saved.EnrichmentStatus = status
+	}
+	records := r.enrichments[after.ID]
+	for _, record := range after.Enrichments[min(len(before.Enrichments), len(after.Enrichments)):] {
+		record.PersonID = after.ID
+		record.ID = uint(len(records) + 1)
+		records = append(records, record)
+	}
+	if len(records) > 0 {
+		r.enrichments[after.ID] = records
+	}
+	if len(models.DiffPeople(&current, saved)) == 0 {
+		return nil
+	}
+	saved.Version++
+	saved.UpdatedAt = time.Now()
+	r.people[after.ID] = stored(saved)
+	r.record(WithActor(ctx, EnrichmentActor), models.VersionEnrich, &current, saved)
+	return nil
+}
+
+// Enqueue добавляет задачу в очередь человека или переносит уже ожидающую на runAfter,
+// если это раньше
+func (r *MemoryPersonRepository) Enqueue(ctx context.Context, personID uint, runAfter time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	jobs := r.jobs[personID]
+	for i, job := range jobs {
+		if job.Status != models.EnrichmentPending {
+			continue
+		}
+		if runAfter.Before(job.RunAfter) {
+			// Копия, чтобы откат транзакции не видел изменения
+			jobs = slices.Clone(jobs)
+			jobs[i].RunAfter = runAfter
+			jobs[i].UpdatedAt = now
+			r.jobs[personID] = jobs
+		}
+		return nil
+	}
+	r.nextJobID++
+	r.jobs[personID] = append(r.jobs[personID], models.EnrichmentJob{
+		ID:        r.nextJobID,
+		PersonID:  personID,
+		Status:    models.EnrichmentPending,
+		RunAfter:  runAfter,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	return nil
+}
+
+// EnrichmentStatus возвращает состояние обогащения по последней задаче человека
+func (r *MemoryPersonRepository) EnrichmentStatus(ctx context.Context, person models.Person) (models.EnrichmentStatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	jobs := r.jobs[person.ID]
+	if len(jobs) == 0 {
+		return jobStatus(person, nil), nil
+	}
+	return jobStatus(person, &jobs[len(jobs)-1]), nil
+}
+
+// Ping всегда успешен: хранилище в памяти процесса
+func (r *MemoryPersonRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Transaction выполняет fn и восстанавливает прежнее состояние, если fn вернула ошибку
+func (r *MemoryPersonRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+	r.mu.RLock()
+	nextID, people, enrichments, versions := r.nextID, maps.Clone(r.people), maps.Clone(r.enrichments), maps.Clone(r.versions)
+	nextJobID, jobs := r.nextJobID, maps.Clone(r.jobs)
+	r.mu.RUnlock()
+	if err := fn(ctx); err != nil {
+		r.mu.Lock()
+		r.nextID, r.people, r.enrichments, r.versions = nextID, people, enrichments, versions
+		r.nextJobID, r.jobs = nextJobID, jobs
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// stored копия человека для хранения: без связанных записей и с собственным возрастом
+func stored(person models.Person) models.Person {
+	person.Enrichments = nil
+	if person.Age != nil {
+		age := *person.Age
+		person.Age = &age
+	}
+	return person
+}
@@ -0,0 +1,75 @@
+// Package repository отделяет хранение людей от HTTP-обработчиков
+package repository
+
+import (
+	"context"
+	"errors"
+	"person-api/models"
+	"time"
+)
+
+// ErrNotFound человек с указанным ID не найден
+var ErrNotFound = errors.New("не найден")
+
+// ErrVersionNotFound у человека нет версии с указанным номером
+var ErrVersionNotFound = errors.New("версия не найдена")
+
+// ErrConflict запись изменилась: её версия не совпадает с ожидаемой
+var ErrConflict = errors.New("запись изменена другим запросом")
+
+// PersonFilter фильтры и пагинация списка людей
+type PersonFilter struct {
+	Name        string // Подстрока имени без учёта регистра
+	Surname     string // Подстрока фамилии без учёта регистра
+	Age         *int   // Точный возраст
+	Gender      string // Пол
+	Nationality string // Национальность
+	// Включая удалённых (только для администраторов)
+	IncludeDeleted bool
+	Offset         int // Смещение
+	Limit          int // Ограничение (0 — без ограничения)
+}
+
+// PersonRepository хранилище людей
+type PersonRepository interface {
+	// Create сохраняет нового человека вместе с записями о происхождении и заполняет ID
+	Create(ctx context.Context, person *models.Person) error
+	// Get возвращает человека по ID или ErrNotFound (в том числе для удалённого)
+	Get(ctx context.Context, id uint) (*models.Person, error)
+	// FindID возвращает внутренний ID человека по публичному идентификатору (в том числе удалённого)
+	// или ErrNotFound
+	FindID(ctx context.Context, publicID string) (uint, error)
+	// List возвращает людей по фильтру в порядке ID
+	List(ctx context.Context, filter PersonFilter) ([]models.Person, error)
+	// Count возвращает количество людей по фильтру без учёта пагинации
+	Count(ctx context.Context, filter PersonFilter) (int64, error)
+	// LastModified возвращает наибольшее время изменения людей по фильтру
+	// (нулевое, если подходящих нет)
+	LastModified(ctx context.Context, filter PersonFilter) (time.Time, error)
+	// Update сохраняет все поля человека и увеличивает person.Version; если в хранилище
+	// другая версия (запись изменили после чтения), возвращает ErrConflict
+	Update(ctx context.Context, person *models.Person) error
+	// Delete помечает человека удалённым; запись можно восстановить до очистки.
+	// version — ожидаемая версия записи (0 — любая), при несовпадении ErrConflict.
+	// Если человека нет или он уже удалён, возвращает ErrNotFound
+	Delete(ctx context.Context, id uint, version int) error
+	// Restore восстанавливает удалённого человека или возвращает ErrNotFound
+	Restore(ctx context.Context, id uint) error
+	// Purge окончательно удаляет людей, удалённых раньше before, и возвращает их количество
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// History возвращает историю изменений человека по возрастанию номера версии
+	History(ctx context.Context, id uint) ([]models.PersonVersion, error)
+	// AsOf возвращает состояние человека на момент at или ErrNotFound, если его тогда не было
+	AsOf(ctx context.Context, id uint, at time.Time) (*models.Person, error)
+	// Revert возвращает редактируемые поля человека к версии number и записывает это новой версией;
+	// ErrNotFound — человека нет (или он удалён), ErrVersionNotFound — нет такой версии
+	Revert(ctx context.Context, id uint, number int) (*models.Person, error)
+	// Enrichments возвращает записи о происхождении данных человека по времени запроса
+	Enrichments(ctx context.Context, id uint) ([]models.PersonEnrichment, error)
+	// Transaction выполняет fn атомарно; методы, вызванные с переданным ctx, входят в транзакцию
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Ping проверяет доступность хранилища
+	Ping(ctx context.Context) error
+	// Результаты и очередь фонового обогащения
+	EnrichmentRepository
+}
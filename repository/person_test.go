@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"person-api/database"
+	"person-api/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repositories возвращает реализации PersonRepository для общих тестов
+func repositories(t *testing.T) map[string]PersonRepository {
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, Path: ":memory:"})
+	require.NoError(t, err)
+	require.NoError(t, database.RunMigrations(db))
+	return map[string]PersonRepository{
+		"gorm":   NewGormPersonRepository(db),
+		"memory": NewMemoryPersonRepository(),
+	}
+}
+
+// seed создаёт людей и возвращает их в порядке создания
+func seed(t *testing.T, repo PersonRepository) []models.Person {
+	age := 42
+	people := []models.Person{
+		{Name: "Дмитрий", Surname: "Ушаков", Gender: "male", Nationality: "RU", Age: &age},
+		{Name: "Анна", Surname: "Ушакова", Gender: "female", Nationality: "RU"},
+		{Name: "Иван", Surname: "Петров", Gender: "male", Nationality: "UA"},
+	}
+	for i := range people {
+		require.NoError(t, repo.Create(context.Background(), &people[i]))
+		require.NotZero(t, people[i].ID)
+	}
+	return people
+}
+
+// TestPersonRepositoryCRUD проверяет создание, чтение, обновление и удаление
+func TestPersonRepositoryCRUD(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков", Enrichments: []models.PersonEnrichment{
+				{Provider: "genderize", Field: "gender", Value: "male", Accepted: true},
+			}}
+			require.NoError(t, repo.Create(ctx, &person))
+			assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, person.PublicID)
+
+			got, err := repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Дмитрий", got.Name)
+			assert.Equal(t, person.PublicID, got.PublicID)
+			assert.Empty(t, got.Enrichments)
+
+			id, err := repo.FindID(ctx, person.PublicID)
+			require.NoError(t, err)
+			assert.Equal(t, person.ID, id)
+			_, err = repo.FindID(ctx, "01912d68-783e-7a03-8f4e-2c5b9a3d7e10")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			records, err := repo.Enrichments(ctx, person.ID)
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			assert.Equal(t, "genderize", records[0].Provider)
+
+			got.Name = "Иван"
+			require.NoError(t, repo.Update(ctx, got))
+			got, err = repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Иван", got.Name)
+
+			require.NoError(t, repo.Delete(ctx, person.ID, 0))
+			_, err = repo.Get(ctx, person.ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			// Повторное удаление и удаление несуществующего не проходят молча
+			assert.ErrorIs(t, repo.Delete(ctx, person.ID, 0), ErrNotFound)
+			assert.ErrorIs(t, repo.Delete(ctx, 999, 0), ErrNotFound)
+		})
+	}
+}
+
+// TestPersonRepositoryList проверяет фильтры, пагинацию и подсчёт
+func TestPersonRepositoryList(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			people := seed(t, repo)
+			age := 42
+			cases := []struct {
+				filter PersonFilter
+				want   []uint
+			}{
+				{PersonFilter{}, []uint{people[0].ID, people[1].ID, people[2].ID}},
+				{PersonFilter{Gender: "male"}, []uint{people[0].ID, people[2].ID}},
+				{PersonFilter{Surname: "ушаков"}, []uint{people[0].ID, people[1].ID}},
+				{PersonFilter{Name: "ДМИТ"}, []uint{people[0].ID}},
+				{PersonFilter{Nationality: "RU", Gender: "female"}, []uint{people[1].ID}},
+				{PersonFilter{Age: &age}, []uint{people[0].ID}},
+				{PersonFilter{Offset: 1, Limit: 1}, []uint{people[1].ID}},
+				{PersonFilter{Offset: 5}, nil},
+			}
+			for _, tc := range cases {
+				list, err := repo.List(ctx, tc.filter)
+				require.NoError(t, err)
+				var ids []uint
+				for _, p := range list {
+					ids = append(ids, p.ID)
+				}
+				assert.Equal(t, tc.want, ids, "%+v", tc.filter)
+			}
+
+			count, err := repo.Count(ctx, PersonFilter{Gender: "male", Offset: 1, Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+		})
+	}
+}
+
+// TestPersonRepositoryLastModified проверяет время последнего изменения по фильтру
+func TestPersonRepositoryLastModified(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			last, err := repo.LastModified(ctx, PersonFilter{})
+			require.NoError(t, err)
+			assert.True(t, last.IsZero())
+
+			people := seed(t, repo)
+			time.Sleep(5 * time.Millisecond)
+			people[0].Patronymic = "Васильевич"
+			require.NoError(t, repo.Update(ctx, &people[0]))
+			last, err = repo.LastModified(ctx, PersonFilter{})
+			require.NoError(t, err)
+			assert.WithinDuration(t, people[0].UpdatedAt, last, time.Millisecond)
+			// Изменённый человек не подходит под фильтр
+			last, err = repo.LastModified(ctx, PersonFilter{Surname: "Петров"})
+			require.NoError(t, err)
+			assert.True(t, last.Before(people[0].UpdatedAt))
+		})
+	}
+}
+
+// TestPersonRepositoryTransaction проверяет откат изменений при ошибке
+func TestPersonRepositoryTransaction(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			failure := errors.New("откат")
+			err := repo.Transaction(ctx, func(ctx context.Context) error {
+				person := models.Person{Name: "Дмитрий", Surname: "Ушаков"}
+				require.NoError(t, repo.Create(ctx, &person))
+				return failure
+			})
+			assert.ErrorIs(t, err, failure)
+			count, err := repo.Count(ctx, PersonFilter{})
+			require.NoError(t, err)
+			assert.Zero(t, count)
+
+			require.NoError(t, repo.Transaction(ctx, func(ctx context.Context) error {
+				person := models.Person{Name: "Дмитрий", Surname: "Ушаков"}
+				return repo.Create(ctx, &person)
+			}))
+			count, err = repo.Count(ctx, PersonFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+		})
+	}
+}
+
+// TestPersonRepositorySoftDelete проверяет мягкое удаление, восстановление и очистку
+func TestPersonRepositorySoftDelete(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			people := seed(t, repo)
+			assert.False(t, people[0].CreatedAt.IsZero())
+			require.NoError(t, repo.Delete(ctx, people[0].ID, 0))
+			require.NoError(t, repo.Delete(ctx, people[1].ID, 0))
+
+			_, err := repo.Get(ctx, people[0].ID)
+			assert.ErrorIs(t, err, ErrNotFound)
+			count, err := repo.Count(ctx, PersonFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			list, err := repo.List(ctx, PersonFilter{IncludeDeleted: true})
+			require.NoError(t, err)
+			require.Len(t, list, 3)
+			assert.True(t, list[0].DeletedAt.Valid)
+
+			require.NoError(t, repo.Restore(ctx, people[0].ID))
+			assert.ErrorIs(t, repo.Restore(ctx, people[0].ID), ErrNotFound)
+			assert.ErrorIs(t, repo.Restore(ctx, 999), ErrNotFound)
+			got, err := repo.Get(ctx, people[0].ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Дмитрий", got.Name)
+
+			// Удалённые позже границы остаются
+			purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+			require.NoError(t, err)
+			assert.Zero(t, purged)
+			purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), purged)
+			count, err = repo.Count(ctx, PersonFilter{IncludeDeleted: true})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+		})
+	}
+}
+
+// TestPersonRepositoryHistory проверяет историю версий, чтение на момент времени и возврат к версии
+func TestPersonRepositoryHistory(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := WithActor(context.Background(), "support:ivanov")
+			before := time.Now()
+			time.Sleep(5 * time.Millisecond)
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков"}
+			require.NoError(t, repo.Create(ctx, &person))
+			time.Sleep(5 * time.Millisecond)
+			created := time.Now()
+			time.Sleep(5 * time.Millisecond)
+
+			person.Name = "Иван"
+			require.NoError(t, repo.Update(ctx, &person))
+			// Сохранение без изменений не создаёт версию
+			require.NoError(t, repo.Update(ctx, &person))
+			require.NoError(t, repo.Delete(ctx, person.ID, 0))
+
+			versions, err := repo.History(ctx, person.ID)
+			require.NoError(t, err)
+			require.Len(t, versions, 3)
+			assert.Equal(t, []string{models.VersionCreate, models.VersionUpdate, models.VersionDelete},
+				[]string{versions[0].Operation, versions[1].Operation, versions[2].Operation})
+			assert.Equal(t, 2, versions[1].Version)
+			assert.Equal(t, "support:ivanov", versions[1].Actor)
+			assert.Equal(t, models.FieldChange{Old: "Дмитрий", New: "Иван"}, versions[1].Changes["name"])
+			assert.Len(t, versions[1].Changes, 1)
+			assert.Equal(t, "Иван", versions[1].Snapshot.Name)
+
+			// На момент до создания и после удаления человека нет
+			_, err = repo.AsOf(ctx, person.ID, before)
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = repo.AsOf(ctx, person.ID, time.Now())
+			assert.ErrorIs(t, err, ErrNotFound)
+			got, err := repo.AsOf(ctx, person.ID, created)
+			require.NoError(t, err)
+			assert.Equal(t, "Дмитрий", got.Name)
+
+			// Удалённого нельзя вернуть к версии, пока его не восстановят
+			_, err = repo.Revert(ctx, person.ID, 1)
+			assert.ErrorIs(t, err, ErrNotFound)
+			require.NoError(t, repo.Restore(ctx, person.ID))
+			_, err = repo.Revert(ctx, person.ID, 10)
+			assert.ErrorIs(t, err, ErrVersionNotFound)
+			reverted, err := repo.Revert(ctx, person.ID, 1)
+			require.NoError(t, err)
+			assert.Equal(t, "Дмитрий", reverted.Name)
+			got, err = repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Дмитрий", got.Name)
+
+			versions, err = repo.History(ctx, person.ID)
+			require.NoError(t, err)
+			require.Len(t, versions, 5)
+			assert.Equal(t, models.VersionRestore, versions[3].Operation)
+			assert.Equal(t, models.VersionRevert, versions[4].Operation)
+			assert.Equal(t, models.FieldChange{Old: "Иван", New: "Дмитрий"}, versions[4].Changes["name"])
+		})
+	}
+}
+
+// TestPersonRepositoryConflict проверяет увеличение версии записи и отказ при устаревшей версии
+func TestPersonRepositoryConflict(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			person := models.Person{Name: "Дмитрий", Surname: "Ушаков"}
+			require.NoError(t, repo.Create(ctx, &person))
+			assert.Equal(t, 1, person.Version)
+
+			first, err := repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			second, err := repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			first.Name = "Иван"
+			require.NoError(t, repo.Update(ctx, first))
+			assert.Equal(t, 2, first.Version)
+			// Второй клиент читал версию 1 и не должен затереть изменение первого
+			second.Surname = "Петров"
+			assert.ErrorIs(t, repo.Update(ctx, second), ErrConflict)
+			assert.Equal(t, 1, second.Version)
+			got, err := repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Иван", got.Name)
+			assert.Equal(t, "Ушаков", got.Surname)
+			assert.Equal(t, 2, got.Version)
+
+			assert.ErrorIs(t, repo.Delete(ctx, person.ID, 1), ErrConflict)
+			require.NoError(t, repo.Delete(ctx, person.ID, 2))
+			require.NoError(t, repo.Restore(ctx, person.ID))
+			got, err = repo.Get(ctx, person.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 4, got.Version)
+		})
+	}
+}
+
+// TestMemoryPersonRepositoryCopies проверяет, что изменения полученной копии не затрагивают хранилище
+func TestMemoryPersonRepositoryCopies(t *testing.T) {
+	repo := NewMemoryPersonRepository()
+	ctx := context.Background()
+	age := 42
+	person := models.Person{Name: "Дмитрий", Surname: "Ушаков", Age: &age}
+	require.NoError(t, repo.Create(ctx, &person))
+
+	got, err := repo.Get(ctx, person.ID)
+	require.NoError(t, err)
+	*got.Age = 10
+	got.Name = "Иван"
+
+	got, err = repo.Get(ctx, person.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Дмитрий", got.Name)
+	assert.Equal(t, 42, *got.Age)
+
+	list, err := repo.List(ctx, PersonFilter{Surname: "УША"})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PurgeConfig настройки окончательного удаления людей, помеченных удалёнными
+type PurgeConfig struct {
+	Retention time.Duration // Сколько хранить удалённых (0 — не удалять окончательно)
+	Interval  time.Duration // Как часто запускать очистку
+}
+
+// DefaultPurgeConfig настройки очистки по умолчанию
+var DefaultPurgeConfig = PurgeConfig{
+	Retention: 30 * 24 * time.Hour,
+	Interval:  time.Hour,
+}
+
+// LoadPurgeConfig читает PEOPLE_RETENTION и PEOPLE_PURGE_INTERVAL
+func LoadPurgeConfig() PurgeConfig {
+	cfg := DefaultPurgeConfig
+	cfg.Retention = durationFromEnv("PEOPLE_RETENTION", cfg.Retention)
+	cfg.Interval = durationFromEnv("PEOPLE_PURGE_INTERVAL", cfg.Interval)
+	return cfg
+}
+
+// durationFromEnv читает длительность из переменной окружения
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		logrus.Warnf("Некорректное значение %s=%q, используется %s", key, value, def)
+		return def
+	}
+	return d
+}
+
+// RunPurge периодически окончательно удаляет людей, удалённых дольше срока хранения,
+// пока не отменён ctx
+func RunPurge(ctx context.Context, people PersonRepository, cfg PurgeConfig) {
+	if cfg.Retention == 0 || cfg.Interval == 0 {
+		logrus.Info("Очистка удалённых людей отключена")
+		return
+	}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		purged, err := people.Purge(ctx, time.Now().Add(-cfg.Retention))
+		if err != nil {
+			logrus.Errorf("Ошибка очистки удалённых людей: %v", err)
+		} else if purged > 0 {
+			logrus.Infof("Окончательно удалено людей: %d", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
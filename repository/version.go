@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"person-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type actorKey struct{}
+
+// WithActor сохраняет в ctx, кто выполняет изменения (попадает в историю версий)
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor возвращает автора изменений из ctx или пустую строку
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// newVersion создаёт следующую версию человека. Для изменений без отличий
+// от прежнего состояния возвращает false: пустые версии не сохраняются.
+func newVersion(operation, actor string, before *models.Person, after models.Person, number int) (models.PersonVersion, bool) {
+	changes := models.DiffPeople(before, after)
+	if before != nil && len(changes) == 0 {
+		return models.PersonVersion{}, false
+	}
+	after.Enrichments = nil
+	return models.PersonVersion{
+		PersonID:  after.ID,
+		Version:   number,
+		Operation: operation,
+		Actor:     actor,
+		Snapshot:  after,
+		Changes:   changes,
+		// UTC, чтобы в SQLite время сравнивалось как строка корректно (для ?as_of)
+		CreatedAt: time.Now().UTC(),
+	}, true
+}
+
+// RecordVersion добавляет в историю версию after; before — состояние до изменения
+// (nil для нового человека). Вызывается в той же транзакции, что и само изменение.
+func RecordVersion(db *gorm.DB, operation, actor string, before *models.Person, after models.Person) error {
+	var last int
+	err := db.Model(&models.PersonVersion{}).Where("person_id = ?", after.ID).
+		Select("COALESCE(MAX(version), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+	version, ok := newVersion(operation, actor, before, after, last+1)
+	if !ok {
+		return nil
+	}
+	return db.Create(&version).Error
+}
+
+// snapshotPerson возвращает снимок версии или ErrNotFound, если в тот момент человек был удалён
+func snapshotPerson(version models.PersonVersion) (*models.Person, error) {
+	if version.Snapshot.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	person := version.Snapshot
+	// Внутренний ID не сериализуется в снимок
+	person.ID = version.PersonID
+	return &person, nil
+}
+
+// applySnapshot переносит в person редактируемые поля из снимка версии.
+// ID, статус обогащения, время создания и удаления не меняются.
+func applySnapshot(person *models.Person, snapshot models.Person) {
+	person.Name = snapshot.Name
+	person.Surname = snapshot.Surname
+	person.Patronymic = snapshot.Patronymic
+	person.Age = snapshot.Age
+	person.Gender = snapshot.Gender
+	person.Nationality = snapshot.Nationality
+	person.AgeSource = snapshot.AgeSource
+	person.GenderSource = snapshot.GenderSource
+	person.NationalitySource = snapshot.NationalitySource
+}